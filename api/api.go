@@ -2,8 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,7 +32,10 @@ func init() {
 
 // GetServiceInfoAPI returns the service information
 func GetServiceInfoAPI(w http.ResponseWriter, r *http.Request) {
-	jsonObjStr, _ := json.Marshal(common.GetServiceInfo())
+	serviceInfo := common.GetServiceInfo()
+	serviceInfo.Labels = timeseries.DefaultLabelsMap()
+
+	jsonObjStr, _ := json.Marshal(serviceInfo)
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(jsonObjStr)
 }
@@ -44,12 +51,50 @@ func GetServiceStatistics(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(jsonMetrics))
 }
 
+// GetGoRoutinesStats returns the goroutines parsed from their stacks, grouped by identical stacks.
+// /monigo/api/v1/go-routines-stats?state=chan%20receive&function=net/http.&min_wait=5m&group=false
+// The optional state, function and min_wait query parameters filter the goroutines, and group=false returns
+// the goroutines one by one instead of the groups.
 func GetGoRoutinesStats(w http.ResponseWriter, r *http.Request) {
-	jsonGoRoutinesStats, _ := json.Marshal(core.CollectGoRoutinesInfo())
+	query := r.URL.Query()
+	filter := core.GoroutineFilter{State: query.Get("state"), Function: query.Get("function")}
+	if value := query.Get("min_wait"); value != "" {
+		minWait, err := time.ParseDuration(value)
+		if err != nil || minWait < 0 {
+			http.Error(w, "Invalid min_wait, expected a duration ex. 5m", http.StatusBadRequest)
+			return
+		}
+		filter.MinWait = minWait
+	}
+	group := true
+	if value := query.Get("group"); value != "" {
+		var err error
+		if group, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid group, expected true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	jsonGoRoutinesStats, _ := json.Marshal(core.AnalyzeGoroutines(filter, group))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(jsonGoRoutinesStats))
 }
 
+// GetGoroutineLeaks returns the goroutine creation sites suspected of leaking goroutines.
+// /monigo/api/v1/goroutine-leaks?all=true
+// With all=true every tracked creation site is returned along with the suspects.
+func GetGoroutineLeaks(w http.ResponseWriter, r *http.Request) {
+	all := false
+	if value := r.URL.Query().Get("all"); value != "" {
+		var err error
+		if all, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid all, expected true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, core.GetGoroutineLeaks(all))
+}
+
 var NameMap = map[string]string{
 	"heap_alloc":      "HeapAlloc",
 	"heap_sys":        "HeapSys",
@@ -91,19 +136,18 @@ func GetServiceMetricsFromStorage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	serviceStartTime := common.GetServiceStartTime()
+	dataStartTime := common.GetDataStartTime()
 
-	if startTime.Before(serviceStartTime) {
-		startTime = serviceStartTime
+	if startTime.Before(dataStartTime) {
+		startTime = dataStartTime
 	}
 
-	labelName := "host"
-	labelValue := "server1"
+	labels := timeseries.MatchLabels(req.Labels)
 
 	dataByTimestamp := make(map[int64]map[string]float64)
 
 	for _, fieldName := range req.FieldName {
-		datapoints, err := timeseries.GetDataPoints(fieldName, []tstorage.Label{{Name: labelName, Value: labelValue}}, startTime.Unix(), endTime.Unix())
+		datapoints, err := timeseries.GetDataPoints(fieldName, labels, startTime.Unix(), endTime.Unix())
 		if err != nil {
 			http.Error(w, "Failed to get data points", http.StatusInternalServerError)
 			return
@@ -165,15 +209,17 @@ func GetReportData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	serviceStartTime := common.GetServiceStartTime()
+	dataStartTime := common.GetDataStartTime()
 
-	if startTime.Before(serviceStartTime) {
-		startTime = serviceStartTime
+	if startTime.Before(dataStartTime) {
+		startTime = dataStartTime
 	}
 
+	labels := timeseries.MatchLabels(reqObj.Labels)
+
 	var fieldNameList []string
 	if reqObj.Topic == "LoadStatistics" {
-		fieldNameList = []string{"overall_load_of_service", "service_cpu_load", "service_memory_load", "system_cpu_load", "system_memory_load"}
+		fieldNameList = []string{"overall_load_of_service", "service_cpu_load", "service_memory_load", "system_cpu_load", "system_memory_load", "service_disk_load", "system_disk_load", "total_disk_load"}
 	} else if reqObj.Topic == "CPUStatistics" {
 		fieldNameList = []string{"total_cores", "cores_used_by_service", "cores_used_by_system"}
 	} else if reqObj.Topic == "MemoryStatistics" {
@@ -181,18 +227,33 @@ func GetReportData(w http.ResponseWriter, r *http.Request) {
 	} else if reqObj.Topic == "MemoryProfile" {
 		fieldNameList = []string{"heap_alloc_by_service", "heap_alloc_by_system", "total_alloc_by_service", "total_memory_by_os"}
 	} else if reqObj.Topic == "NetworkIO" {
-		fieldNameList = []string{"bytes_sent", "bytes_received"}
+		fieldNameList = []string{"bytes_sent_per_sec", "bytes_received_per_sec"}
+	} else if reqObj.Topic == "RuntimeStatistics" {
+		fieldNameList = []string{"runtime_sched_latency_p50", "runtime_sched_latency_p90", "runtime_sched_latency_p99", "runtime_gc_pause_p50", "runtime_gc_pause_p90", "runtime_gc_pause_p99", "runtime_heap_live", "runtime_heap_goal", "runtime_memory_limit", "runtime_mutex_wait"}
+	} else if reqObj.Topic == "DiskIO" {
+		fieldNameList = []string{"process_read_bytes_per_sec", "process_write_bytes_per_sec", "filesystem_used_bytes", "filesystem_used_percent"}
 	} else if reqObj.Topic == "OverallHealth" {
 		fieldNameList = []string{"service_health_percent", "system_health_percent"}
 	}
 
-	labelName := "host"
-	labelValue := "server1"
+	var queries []seriesQuery
+	for _, fieldName := range fieldNameList {
+		queries = append(queries, seriesQuery{Field: fieldName, Metric: fieldName, Labels: labels})
+	}
+	if reqObj.Topic == "NetworkIO" {
+		queries = append(queries, networkInterfaceQueries(labels)...)
+	}
+	if reqObj.Topic == "DiskIO" {
+		queries = append(queries, diskDeviceQueries(labels)...)
+	}
+	if reqObj.Topic == "CustomMetrics" {
+		queries = customMetricsQueries(labels)
+	}
 
 	dataByTimestamp := make(map[int64]map[string]float64)
-	for _, fieldName := range fieldNameList {
+	for _, query := range queries {
 
-		datapoints, err := timeseries.GetDataPoints(fieldName, []tstorage.Label{{Name: labelName, Value: labelValue}}, startTime.Unix(), endTime.Unix())
+		datapoints, err := timeseries.GetDataPoints(query.Metric, query.Labels, startTime.Unix(), endTime.Unix())
 		if err != nil {
 			http.Error(w, "Failed to get data points", http.StatusInternalServerError)
 			return
@@ -202,7 +263,7 @@ func GetReportData(w http.ResponseWriter, r *http.Request) {
 			if _, exists := dataByTimestamp[dp.Timestamp]; !exists {
 				dataByTimestamp[dp.Timestamp] = make(map[string]float64)
 			}
-			dataByTimestamp[dp.Timestamp][fieldName] = dp.Value
+			dataByTimestamp[dp.Timestamp][query.Field] = dp.Value
 		}
 
 	}
@@ -229,26 +290,213 @@ func GetReportData(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonDP)
 }
 
-// GetFunctionTraceDetails returns the function trace details
+// seriesQuery is a series queried for a report, Field is the name of the column in the report
+type seriesQuery struct {
+	Field  string
+	Metric string
+	Labels []tstorage.Label
+}
+
+// customMetricsQueries returns the series of every custom metric, histograms are reported by their count and percentiles
+func customMetricsQueries(seriesLabels []tstorage.Label) []seriesQuery {
+	var queries []seriesQuery
+	for _, metric := range core.GetCustomMetrics() {
+		labels := timeseries.CustomMetricLabels(metric, seriesLabels)
+		field := metric.Name
+		if len(metric.Labels) > 0 {
+			pairs := make([]string, 0, len(metric.Labels))
+			for name, value := range metric.Labels {
+				pairs = append(pairs, name+"="+value)
+			}
+			sort.Strings(pairs)
+			field += "{" + strings.Join(pairs, ",") + "}"
+		}
+
+		if metric.Type != core.HistogramMetric {
+			queries = append(queries, seriesQuery{Field: field, Metric: metric.Name, Labels: labels})
+			continue
+		}
+		for _, suffix := range []string{"_count", "_p50", "_p90", "_p99"} {
+			queries = append(queries, seriesQuery{Field: field + suffix, Metric: metric.Name + suffix, Labels: labels})
+		}
+	}
+	return queries
+}
+
+// networkInterfaceQueries returns the throughput series of every interface, the field is suffixed with the interface name
+func networkInterfaceQueries(seriesLabels []tstorage.Label) []seriesQuery {
+	var queries []seriesQuery
+	for _, iface := range core.GetServiceStats().NetworkIO.Interfaces {
+		labels := append(append([]tstorage.Label{}, seriesLabels...), tstorage.Label{Name: "interface", Value: iface.Name})
+		for _, metric := range []string{"network_bytes_sent_per_sec", "network_bytes_received_per_sec"} {
+			queries = append(queries, seriesQuery{Field: metric + "{interface=" + iface.Name + "}", Metric: metric, Labels: labels})
+		}
+	}
+	return queries
+}
+
+// diskDeviceQueries returns the throughput and utilization series of every disk, the field is suffixed with the device name
+func diskDeviceQueries(seriesLabels []tstorage.Label) []seriesQuery {
+	var queries []seriesQuery
+	for _, device := range core.GetServiceStats().DiskIO.Devices {
+		labels := append(append([]tstorage.Label{}, seriesLabels...), tstorage.Label{Name: "device", Value: device.Name})
+		for _, metric := range []string{"disk_read_bytes_per_sec", "disk_write_bytes_per_sec", "disk_utilization_percent"} {
+			queries = append(queries, seriesQuery{Field: metric + "{device=" + device.Name + "}", Metric: metric, Labels: labels})
+		}
+	}
+	return queries
+}
+
+// GetServiceEvents returns the lifecycle events of the service, ex. the restarts.
+// The optional start_time and end_time query parameters ("2006-01-02T15:04:05Z07:00") limit the range.
+func GetServiceEvents(w http.ResponseWriter, r *http.Request) {
+	var startTime, endTime time.Time
+	var err error
+	if value := r.URL.Query().Get("start_time"); value != "" {
+		if startTime, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, "Invalid start time", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := r.URL.Query().Get("end_time"); value != "" {
+		if endTime, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, "Invalid end time", http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, err := timeseries.GetEvents(startTime, endTime)
+	if err != nil {
+		http.Error(w, "Failed to get events", http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []models.ServiceEvent{}
+	}
+
+	jsonEvents, err := json.Marshal(events)
+	if err != nil {
+		http.Error(w, "Failed to marshal events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonEvents)
+}
+
+// functionTrendMetrics are the stored statistics returned in the trend of a traced function
+var functionTrendMetrics = []string{
+	"function_calls",
+	"function_errors",
+	"function_execution_time_mean",
+	"function_execution_time_p95",
+	"function_execution_time_p99",
+	"function_allocated_bytes_mean",
+	"function_allocated_bytes_p95",
+}
+
+// GetFunctionTraceDetails returns the statistics of the traced functions.
+// The optional name query parameter selects a function, and start_time ("2006-01-02T15:04:05Z07:00") adds the stored trend
+// of every function up to end_time, or now.
 func GetFunctionTraceDetails(w http.ResponseWriter, r *http.Request) {
-	jsonObjStr, _ := json.Marshal(core.FunctionTraceDetails())
+	details := core.FunctionTraceDetails()
+	if name := r.URL.Query().Get("name"); name != "" {
+		metrics, ok := details[name]
+		if !ok {
+			http.Error(w, "Function not found", http.StatusNotFound)
+			return
+		}
+		details = map[string]*models.FunctionMetrics{name: metrics}
+	}
+
+	if value := r.URL.Query().Get("start_time"); value != "" {
+		startTime, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid start time", http.StatusBadRequest)
+			return
+		}
+		endTime := time.Now()
+		if value := r.URL.Query().Get("end_time"); value != "" {
+			if endTime, err = time.Parse(time.RFC3339, value); err != nil {
+				http.Error(w, "Invalid end time", http.StatusBadRequest)
+				return
+			}
+		}
+		if dataStartTime := common.GetDataStartTime(); startTime.Before(dataStartTime) {
+			startTime = dataStartTime
+		}
+
+		for name, metrics := range details {
+			if metrics.Trend, err = functionTrend(name, startTime, endTime); err != nil {
+				http.Error(w, "Failed to get data points", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	jsonObjStr, err := json.Marshal(details)
+	if err != nil {
+		http.Error(w, "Failed to marshal function metrics", http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(jsonObjStr)
 }
 
-// /monigo/api/v1/function-details?name=FunctionName&reportType=text
-func ViewFunctionMaetrtics(w http.ResponseWriter, r *http.Request) {
+// functionTrend returns the statistics of the traced function stored between the start and end time, sorted by time
+func functionTrend(name string, startTime, endTime time.Time) ([]models.FunctionTrendPoint, error) {
+	labels := timeseries.FunctionLabels(name, timeseries.DefaultLabels())
+
+	valuesByTimestamp := make(map[int64]map[string]float64)
+	for _, metric := range functionTrendMetrics {
+		datapoints, err := timeseries.GetDataPoints(metric, labels, startTime.Unix(), endTime.Unix())
+		if err != nil {
+			return nil, err
+		}
+		for _, dp := range datapoints {
+			if _, exists := valuesByTimestamp[dp.Timestamp]; !exists {
+				valuesByTimestamp[dp.Timestamp] = make(map[string]float64)
+			}
+			valuesByTimestamp[dp.Timestamp][metric] = dp.Value
+		}
+	}
 
-	name := r.URL.Query().Get("name")
-	reportType := r.URL.Query().Get("reportType")
+	trend := make([]models.FunctionTrendPoint, 0, len(valuesByTimestamp))
+	for timestamp, values := range valuesByTimestamp {
+		trend = append(trend, models.FunctionTrendPoint{Time: time.Unix(timestamp, 0).UTC(), Values: values})
+	}
+	sort.Slice(trend, func(i, j int) bool { return trend[i].Time.Before(trend[j].Time) })
+	return trend, nil
+}
+
+// /monigo/api/v1/function-details?name=FunctionName&reportType=top
+// The reportType is top, list or tree, the optional limit, focus (function name prefix) and sample_type (heap profile) refine the reports.
+func ViewFunctionMaetrtics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("name")
+	reportType := query.Get("reportType")
 
 	if name == "" {
 		http.Error(w, "Function name is required to get metrics", http.StatusBadRequest)
 		return
 	}
 
-	if reportType == "" {
-		reportType = "text"
+	switch reportType {
+	case "", "text": // text was the report of go tool pprof
+		reportType = core.TopReport
+	case core.TopReport, core.ListReport, core.TreeReport:
+	default:
+		http.Error(w, "Invalid report type, expected top, list or tree", http.StatusBadRequest)
+		return
+	}
+
+	var limit int
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
 	}
 
 	metrics := core.FunctionTraceDetails()[name]
@@ -257,7 +505,110 @@ func ViewFunctionMaetrtics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/plain")
-	jsonResp, _ := json.Marshal(core.ViewFunctionMetrics(name, reportType, metrics))
+	details, err := core.ViewFunctionMetrics(name, core.ProfileReportOptions{
+		Type:       reportType,
+		Focus:      query.Get("focus"),
+		SampleType: query.Get("sample_type"),
+		Limit:      limit,
+	}, metrics)
+
+	jsonResp, marshalErr := json.Marshal(details)
+	if marshalErr != nil {
+		http.Error(w, "Failed to marshal function details", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil { // The reports hold the reason of each profile
+		if errors.Is(err, core.ErrProfileNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
 	w.Write(jsonResp)
 }
+
+// GetFlameGraph returns the flame graph of the CPU or heap profile of the last profiled call of a traced function,
+// or of a profile captured on demand or archived when the id is given instead of the name.
+// /monigo/api/v1/flame-graph?name=FunctionName&profile=cpu&focus=main.&collapse_runtime=true&sample_type=alloc_space
+// /monigo/api/v1/flame-graph?id=cpu-1718000000000000000
+func GetFlameGraph(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	options, err := flameGraphOptions(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if id := query.Get("id"); id != "" {
+		metadata, err := core.GetProfile(id)
+		if errors.Is(err, core.ErrProfileNotFound) {
+			http.Error(w, "Profile not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, "Failed to get the profile", http.StatusInternalServerError)
+			return
+		}
+		if metadata.Kind == core.ProfileTrace {
+			http.Error(w, "Execution traces have no flame graph, download the trace and open it with go tool trace", http.StatusBadRequest)
+			return
+		}
+		writeFlameGraph(w, metadata.Path, options)
+		return
+	}
+
+	name := query.Get("name")
+	if name == "" {
+		http.Error(w, "Function name or profile id is required to get the flame graph", http.StatusBadRequest)
+		return
+	}
+
+	metrics := core.FunctionTraceDetails()[name]
+	if metrics == nil {
+		http.Error(w, "Function not found", http.StatusNotFound)
+		return
+	}
+
+	var path string
+	switch query.Get("profile") {
+	case "", "cpu":
+		path = metrics.CPUProfileFilePath
+	case "heap", "mem":
+		path = metrics.MemProfileFilePath
+	default:
+		http.Error(w, "Invalid profile, expected cpu or heap", http.StatusBadRequest)
+		return
+	}
+	writeFlameGraph(w, path, options)
+}
+
+// flameGraphOptions returns the focus, collapse_runtime and sample_type options of a flame graph request.
+func flameGraphOptions(query url.Values) (core.FlameGraphOptions, error) {
+	options := core.FlameGraphOptions{Focus: query.Get("focus"), SampleType: query.Get("sample_type")}
+	if value := query.Get("collapse_runtime"); value != "" {
+		collapse, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, errors.New("invalid collapse_runtime, expected true or false")
+		}
+		options.CollapseRuntime = collapse
+	}
+	return options, nil
+}
+
+// writeFlameGraph writes the flame graph of the profile as JSON.
+func writeFlameGraph(w http.ResponseWriter, path string, options core.FlameGraphOptions) {
+	graph, err := core.GenerateFlameGraph(path, options)
+	if errors.Is(err, core.ErrProfileNotFound) {
+		http.Error(w, "Profile not found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, core.ErrUnknownSampleType) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to generate the flame graph", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, graph)
+}
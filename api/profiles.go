@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/iyashjayesh/monigo/core"
+)
+
+// CaptureProfile captures a profile on demand and returns its metadata once stored.
+// POST /monigo/api/v1/profiles/capture?kind=cpu&seconds=30
+// The kind is cpu, heap, allocs, block, mutex, goroutine or trace. The optional seconds set the duration of a cpu profile
+// or trace (30 by default) and of the delta of a block or mutex profile, rate sets the block profile rate or mutex
+// profile fraction for the capture, and gc=true runs a garbage collection before a heap or allocs profile.
+func CaptureProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	request := core.CaptureRequest{Kind: query.Get("kind")}
+	if value := query.Get("seconds"); value != "" {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, "Invalid seconds", http.StatusBadRequest)
+			return
+		}
+		request.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	if value := query.Get("rate"); value != "" {
+		rate, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "Invalid rate", http.StatusBadRequest)
+			return
+		}
+		request.Rate = rate
+	}
+	if value := query.Get("gc"); value != "" {
+		gc, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid gc, expected true or false", http.StatusBadRequest)
+			return
+		}
+		request.GC = gc
+	}
+
+	metadata, err := core.CaptureProfile(r.Context(), request)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrProfilerBusy):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case r.Context().Err() != nil:
+			// The client is gone
+		case errors.Is(err, core.ErrInvalidCapture):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, fmt.Sprintf("Failed to capture the profile: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, metadata)
+}
+
+// ListProfiles returns the metadata of the profiles captured on demand, the most recent first.
+func ListProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := core.ListProfiles()
+	if err != nil {
+		http.Error(w, "Failed to list the profiles", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, profiles)
+}
+
+// GetProfileArchive returns the profiles archived by the continuous profiler, the most recent first.
+// /monigo/api/v1/profiles/archive?kind=cpu&start_time=2006-01-02T15:04:05Z&end_time=2006-01-02T16:04:05Z
+// The optional kind, start_time and end_time query parameters filter the profiles. With at=2006-01-02T15:04:05Z
+// the profile of each kind captured the closest to that time is returned instead.
+// The archived profiles are served by the download, report and flame graph APIs like the on-demand profiles.
+func GetProfileArchive(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if value := query.Get("at"); value != "" {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid at time", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, core.NearestArchivedProfiles(at))
+		return
+	}
+
+	var startTime, endTime time.Time
+	var err error
+	if value := query.Get("start_time"); value != "" {
+		if startTime, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, "Invalid start time", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("end_time"); value != "" {
+		if endTime, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, "Invalid end time", http.StatusBadRequest)
+			return
+		}
+	}
+	writeJSON(w, core.ListArchivedProfiles(query.Get("kind"), startTime, endTime))
+}
+
+// DownloadProfile serves the file of a profile captured on demand or archived.
+// /monigo/api/v1/profiles/download?id=cpu-1718000000000000000
+func DownloadProfile(w http.ResponseWriter, r *http.Request) {
+	metadata, err := core.GetProfile(r.URL.Query().Get("id"))
+	if errors.Is(err, core.ErrProfileNotFound) {
+		http.Error(w, "Profile not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to get the profile", http.StatusInternalServerError)
+		return
+	}
+
+	f, err := os.Open(metadata.Path)
+	if err != nil {
+		http.Error(w, "Profile not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", metadata.FileName))
+	http.ServeContent(w, r, metadata.FileName, metadata.CapturedAt, f)
+}
+
+// GetProfileReport returns the top, list or tree report of a profile captured on demand or archived.
+// /monigo/api/v1/profiles/report?id=cpu-1718000000000000000&reportType=top
+// The optional function (list report), limit, focus and sample_type query parameters refine the report.
+func GetProfileReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	metadata, err := core.GetProfile(query.Get("id"))
+	if errors.Is(err, core.ErrProfileNotFound) {
+		http.Error(w, "Profile not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to get the profile", http.StatusInternalServerError)
+		return
+	}
+	if metadata.Kind == core.ProfileTrace {
+		http.Error(w, "Execution traces are not reported, download the trace and open it with go tool trace", http.StatusBadRequest)
+		return
+	}
+
+	options := core.ProfileReportOptions{
+		Type:       query.Get("reportType"),
+		Function:   query.Get("function"),
+		Focus:      query.Get("focus"),
+		SampleType: query.Get("sample_type"),
+	}
+	switch options.Type {
+	case "":
+		options.Type = core.TopReport
+	case core.TopReport, core.TreeReport:
+	case core.ListReport:
+		if options.Function == "" {
+			http.Error(w, "Function is required for the list report", http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Invalid report type, expected top, list or tree", http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		if options.Limit, err = strconv.Atoi(value); err != nil || options.Limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := core.GenerateProfileReport(metadata.Path, options)
+	if errors.Is(err, core.ErrProfileNotFound) {
+		http.Error(w, "Profile not found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, core.ErrUnknownSampleType) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to generate the report", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, report)
+}
+
+// GetProfileDiff compares two stored profiles and returns the change of every function and a differential flame graph.
+// /monigo/api/v1/profiles/diff?base=cpu-1718000000000000000&target=cpu-1718000600000000000&normalize=true
+// A profile is referenced by the id of an on-demand or archived profile, or by a cpu_profile_file_path or
+// mem_profile_file_path of the profiles kept for a traced function. The optional sample_type, focus, collapse_runtime,
+// normalize and limit query parameters refine the comparison.
+func GetProfileDiff(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("base") == "" || query.Get("target") == "" {
+		http.Error(w, "Base and target profiles are required", http.StatusBadRequest)
+		return
+	}
+
+	flameOptions, err := flameGraphOptions(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	options := core.ProfileDiffOptions{
+		SampleType:      flameOptions.SampleType,
+		Focus:           flameOptions.Focus,
+		CollapseRuntime: flameOptions.CollapseRuntime,
+	}
+	if value := query.Get("normalize"); value != "" {
+		if options.Normalize, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid normalize, expected true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if options.Limit, err = strconv.Atoi(value); err != nil || options.Limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var paths [2]string
+	for i, name := range []string{"base", "target"} {
+		paths[i], err = core.ResolveProfile(query.Get(name))
+		if errors.Is(err, core.ErrProfileNotFound) {
+			http.Error(w, fmt.Sprintf("The %s profile was not found", name), http.StatusNotFound)
+			return
+		} else if errors.Is(err, core.ErrExecutionTrace) {
+			http.Error(w, "Execution traces cannot be compared, download the traces and open them with go tool trace", http.StatusBadRequest)
+			return
+		} else if err != nil {
+			http.Error(w, "Failed to get the profiles", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	diff, err := core.DiffProfiles(paths[0], paths[1], options)
+	if errors.Is(err, core.ErrProfileNotFound) {
+		http.Error(w, "Profile not found", http.StatusNotFound)
+		return
+	} else if errors.Is(err, core.ErrUnknownSampleType) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to compare the profiles", http.StatusInternalServerError)
+		return
+	}
+	diff.Base, diff.Target = query.Get("base"), query.Get("target")
+	writeJSON(w, diff)
+}
+
+// writeJSON writes the value as a JSON response.
+func writeJSON(w http.ResponseWriter, value any) {
+	jsonResp, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, "Failed to marshal the response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonResp)
+}
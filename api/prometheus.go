@@ -0,0 +1,450 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/core"
+	"github.com/iyashjayesh/monigo/models"
+	"github.com/iyashjayesh/monigo/timeseries"
+	"github.com/nakabonne/tstorage"
+)
+
+const prometheusNamespace = "monigo"
+
+// promMetric describes how a stored row is exposed in the Prometheus text format.
+type promMetric struct {
+	Name        string                                                     // Exposed name of the family without the namespace, in base units
+	Suffix      string                                                     // Suffix of the samples in the family, ex. "_bucket" for the buckets of a histogram
+	Help        string                                                     // Default is the description of the row name
+	Type        string                                                     // "gauge", "counter", "summary" or "histogram"
+	ExtraLabels []tstorage.Label                                           // Labels added to every sample of the row
+	Value       func(row tstorage.Row, stats *models.ServiceStats) float64 // Converts the row to base units
+	Hidden      bool                                                       // The row is not exposed, ex. the percentiles estimated from the buckets of an exposed histogram
+}
+
+// promSample is a single sample of the exposition.
+type promSample struct {
+	suffix     string
+	labels     string
+	seriesKey  string  // Labels without le and quantile, the samples of a histogram or summary series are written together
+	bound      float64 // Value of the le or quantile label, the buckets and quantiles are written in increasing order
+	value      float64
+	suffixRank int
+}
+
+// promFamily groups the samples sharing the same exposed name.
+type promFamily struct {
+	help       string
+	metricType string
+	samples    map[string]promSample
+}
+
+// summaryMetric returns the representation of a quantile row of a summary.
+func summaryMetric(name, help, quantile string, value func(tstorage.Row, *models.ServiceStats) float64) promMetric {
+	return promMetric{Name: name, Help: help, Type: "summary", ExtraLabels: []tstorage.Label{{Name: "quantile", Value: quantile}}, Value: value}
+}
+
+// histogramMetric returns the representation of the _bucket, _sum or _count row of a histogram.
+func histogramMetric(name, help, suffix string, value func(tstorage.Row, *models.ServiceStats) float64) promMetric {
+	return promMetric{Name: name, Suffix: suffix, Help: help, Type: "histogram", Value: value}
+}
+
+// rowValue returns the value of the row as is.
+func rowValue(row tstorage.Row, _ *models.ServiceStats) float64 {
+	return row.DataPoint.Value
+}
+
+// scaledRowValue returns the value of the row multiplied by the factor.
+func scaledRowValue(factor float64) func(tstorage.Row, *models.ServiceStats) float64 {
+	return func(row tstorage.Row, _ *models.ServiceStats) float64 {
+		return row.DataPoint.Value * factor
+	}
+}
+
+// readableSizeValue returns the human-readable size of the stats field in bytes.
+func readableSizeValue(field func(stats *models.ServiceStats) string) func(tstorage.Row, *models.ServiceStats) float64 {
+	return func(_ tstorage.Row, stats *models.ServiceStats) float64 {
+		bytes, err := common.ConvertReadableSizeToBytes(field(stats))
+		if err != nil {
+			return math.NaN()
+		}
+		return bytes
+	}
+}
+
+var (
+	percentToRatio = scaledRowValue(0.01)
+	msToSeconds    = scaledRowValue(1e-3)
+	rawRecordValue = scaledRowValue(1e3) // Raw memory records are stored in KB (base 1000)
+)
+
+const (
+	httpLatencyHelp          = "Latency of the recent requests of a route in seconds"
+	schedLatencyHelp         = "Time goroutines waited to be scheduled in seconds"
+	schedLatencyIntervalHelp = "Time goroutines waited to be scheduled in seconds, over the last sampling interval"
+	gcPauseHelp              = "Stop-the-world pauses of the GC in seconds"
+	gcPauseIntervalHelp      = "Stop-the-world pauses of the GC in seconds, over the last sampling interval"
+)
+
+// prometheusMetrics maps the stored row names to their Prometheus representation.
+var prometheusMetrics = map[string]promMetric{
+	// Core Statistics
+	"goroutines":                     {Name: "goroutines", Type: "gauge", Value: rowValue},
+	"request_count":                  {Name: "requests_total", Type: "counter", Value: rowValue},
+	"total_duration_took_by_request": {Name: "request_duration_seconds_total", Type: "counter", Value: msToSeconds},
+	"sampler_collection_duration":    {Name: "sampler_collection_duration_seconds", Type: "gauge", Value: msToSeconds},
+	"sampler_overhead_percent":       {Name: "sampler_overhead_ratio", Type: "gauge", Value: percentToRatio},
+	"collector_errors_total":         {Name: "collector_errors_total", Type: "counter", Value: rowValue},
+
+	// Container
+	"container_memory_limit":          {Name: "container_memory_limit_bytes", Type: "gauge", Value: rowValue},
+	"container_memory_usage":          {Name: "container_memory_usage_bytes", Type: "gauge", Value: rowValue},
+	"container_memory_working_set":    {Name: "container_memory_working_set_bytes", Type: "gauge", Value: rowValue},
+	"container_memory_usage_percent":  {Name: "container_memory_usage_ratio", Type: "gauge", Value: percentToRatio},
+	"container_oom_events":            {Name: "container_oom_events_total", Type: "counter", Value: rowValue},
+	"container_oom_kills":             {Name: "container_oom_kills_total", Type: "counter", Value: rowValue},
+	"container_cpu_limit_cores":       {Name: "container_cpu_limit_cores", Type: "gauge", Value: rowValue},
+	"container_cpu_usage_cores":       {Name: "container_cpu_usage_cores", Type: "gauge", Value: rowValue},
+	"container_cpu_usage_percent":     {Name: "container_cpu_usage_ratio", Type: "gauge", Value: percentToRatio},
+	"container_cpu_throttled_periods": {Name: "container_cpu_throttled_periods_total", Type: "counter", Value: rowValue},
+	"container_cpu_throttled_seconds": {Name: "container_cpu_throttled_seconds_total", Type: "counter", Value: rowValue},
+	"http_requests":                   {Name: "http_requests_total", Type: "counter", Value: rowValue},
+	"http_request_latency_p50":        summaryMetric("http_request_latency_seconds", httpLatencyHelp, "0.5", msToSeconds),
+	"http_request_latency_p90":        summaryMetric("http_request_latency_seconds", httpLatencyHelp, "0.9", msToSeconds),
+	"http_request_latency_p99":        summaryMetric("http_request_latency_seconds", httpLatencyHelp, "0.99", msToSeconds),
+
+	// Load Statistics
+	"overall_load_of_service": {Name: "overall_load_of_service_ratio", Type: "gauge", Value: percentToRatio},
+	"service_cpu_load":        {Name: "service_cpu_load_ratio", Type: "gauge", Value: percentToRatio},
+	"service_memory_load":     {Name: "service_memory_load_ratio", Type: "gauge", Value: percentToRatio},
+	"system_cpu_load":         {Name: "system_cpu_load_ratio", Type: "gauge", Value: percentToRatio},
+	"system_memory_load":      {Name: "system_memory_load_ratio", Type: "gauge", Value: percentToRatio},
+	"service_disk_load":       {Name: "service_disk_load_ratio", Type: "gauge", Value: percentToRatio},
+	"system_disk_load":        {Name: "system_disk_load_ratio", Type: "gauge", Value: percentToRatio},
+	"total_disk_load":         {Name: "total_disk_load_ratio", Type: "gauge", Value: percentToRatio},
+
+	// CPU Statistics
+	"total_cores":           {Name: "total_cores", Type: "gauge", Value: rowValue},
+	"cores_used_by_service": {Name: "cores_used_by_service", Type: "gauge", Value: rowValue},
+	"cores_used_by_system":  {Name: "cores_used_by_system", Type: "gauge", Value: rowValue},
+
+	// Memory Statistics
+	"total_system_memory": {Name: "total_system_memory_bytes", Type: "gauge", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.MemoryStatistics.TotalSystemMemory
+	})},
+	"memory_used_by_system": {Name: "memory_used_by_system_bytes", Type: "gauge", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.MemoryStatistics.MemoryUsedBySystem
+	})},
+	"memory_used_by_service": {Name: "memory_used_by_service_bytes", Type: "gauge", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.MemoryStatistics.MemoryUsedByService
+	})},
+	"available_memory": {Name: "available_memory_bytes", Type: "gauge", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.MemoryStatistics.AvailableMemory
+	})},
+	"stack_memory_usage": {Name: "stack_memory_usage_bytes", Type: "gauge", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.MemoryStatistics.StackMemoryUsage
+	})},
+	"gc_pause_duration": {Name: "gc_pause_duration_seconds_total", Type: "counter", Value: msToSeconds},
+
+	// Memory Profile
+	"heap_alloc_by_service": {Name: "heap_alloc_by_service_bytes", Type: "gauge", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.HeapAllocByService
+	})},
+	"heap_alloc_by_system": {Name: "heap_alloc_by_system_bytes", Type: "gauge", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.HeapAllocBySystem
+	})},
+	"total_alloc_by_service": {Name: "total_alloc_by_service_bytes_total", Type: "counter", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.TotalAllocByService
+	})},
+	"total_memory_by_os": {Name: "total_memory_by_os_bytes", Type: "gauge", Value: readableSizeValue(func(s *models.ServiceStats) string {
+		return s.TotalMemoryByOS
+	})},
+
+	// Raw runtime.MemStats records
+	"alloc":           {Name: "memstats_alloc_bytes", Type: "gauge", Value: rawRecordValue},
+	"total_alloc":     {Name: "memstats_alloc_bytes_total", Type: "counter", Value: rawRecordValue},
+	"sys":             {Name: "memstats_sys_bytes", Type: "gauge", Value: rawRecordValue},
+	"lookups":         {Name: "memstats_lookups_total", Type: "counter", Value: rawRecordValue},
+	"mallocs":         {Name: "memstats_mallocs_total", Type: "counter", Value: rawRecordValue},
+	"frees":           {Name: "memstats_frees_total", Type: "counter", Value: rawRecordValue},
+	"heap_alloc":      {Name: "memstats_heap_alloc_bytes", Type: "gauge", Value: rawRecordValue},
+	"heap_sys":        {Name: "memstats_heap_sys_bytes", Type: "gauge", Value: rawRecordValue},
+	"heap_idle":       {Name: "memstats_heap_idle_bytes", Type: "gauge", Value: rawRecordValue},
+	"heap_inuse":      {Name: "memstats_heap_inuse_bytes", Type: "gauge", Value: rawRecordValue},
+	"heap_released":   {Name: "memstats_heap_released_bytes", Type: "gauge", Value: rawRecordValue},
+	"heap_objects":    {Name: "memstats_heap_objects", Type: "gauge", Value: rawRecordValue},
+	"stack_inuse":     {Name: "memstats_stack_inuse_bytes", Type: "gauge", Value: rawRecordValue},
+	"stack_sys":       {Name: "memstats_stack_sys_bytes", Type: "gauge", Value: rawRecordValue},
+	"m_span_inuse":    {Name: "memstats_mspan_inuse_bytes", Type: "gauge", Value: rawRecordValue},
+	"m_span_sys":      {Name: "memstats_mspan_sys_bytes", Type: "gauge", Value: rawRecordValue},
+	"m_cache_inuse":   {Name: "memstats_mcache_inuse_bytes", Type: "gauge", Value: rawRecordValue},
+	"m_cache_sys":     {Name: "memstats_mcache_sys_bytes", Type: "gauge", Value: rawRecordValue},
+	"buck_hash_sys":   {Name: "memstats_buck_hash_sys_bytes", Type: "gauge", Value: rawRecordValue},
+	"gc_sys":          {Name: "memstats_gc_sys_bytes", Type: "gauge", Value: rawRecordValue},
+	"other_sys":       {Name: "memstats_other_sys_bytes", Type: "gauge", Value: rawRecordValue},
+	"next_gc":         {Name: "memstats_next_gc_bytes", Type: "gauge", Value: rawRecordValue},
+	"last_gc":         {Name: "memstats_last_gc_timestamp_seconds", Type: "gauge", Value: scaledRowValue(1e3 / float64(time.Second))},
+	"pause_total_ns":  {Name: "memstats_gc_pause_seconds_total", Type: "counter", Value: scaledRowValue(1e3 / float64(time.Second))},
+	"num_gc":          {Name: "memstats_gc_total", Type: "counter", Value: rawRecordValue},
+	"num_forced_gc":   {Name: "memstats_forced_gc_total", Type: "counter", Value: rawRecordValue},
+	"gc_cpu_fraction": {Name: "memstats_gc_cpu_fraction", Type: "gauge", Value: rawRecordValue},
+
+	// Network IO
+	"bytes_sent":                     {Name: "network_sent_bytes_total", Type: "counter", Value: rowValue},
+	"bytes_received":                 {Name: "network_received_bytes_total", Type: "counter", Value: rowValue},
+	"bytes_sent_per_sec":             {Name: "network_sent_bytes_per_second", Type: "gauge", Value: rowValue},
+	"bytes_received_per_sec":         {Name: "network_received_bytes_per_second", Type: "gauge", Value: rowValue},
+	"network_bytes_sent":             {Name: "network_interface_sent_bytes_total", Type: "counter", Value: rowValue},
+	"network_bytes_received":         {Name: "network_interface_received_bytes_total", Type: "counter", Value: rowValue},
+	"network_packets_sent":           {Name: "network_interface_sent_packets_total", Type: "counter", Value: rowValue},
+	"network_packets_received":       {Name: "network_interface_received_packets_total", Type: "counter", Value: rowValue},
+	"network_errors_in":              {Name: "network_interface_receive_errors_total", Type: "counter", Value: rowValue},
+	"network_errors_out":             {Name: "network_interface_transmit_errors_total", Type: "counter", Value: rowValue},
+	"network_drops_in":               {Name: "network_interface_receive_drops_total", Type: "counter", Value: rowValue},
+	"network_drops_out":              {Name: "network_interface_transmit_drops_total", Type: "counter", Value: rowValue},
+	"network_bytes_sent_per_sec":     {Name: "network_interface_sent_bytes_per_second", Type: "gauge", Value: rowValue},
+	"network_bytes_received_per_sec": {Name: "network_interface_received_bytes_per_second", Type: "gauge", Value: rowValue},
+
+	// Disk IO
+	"process_read_bytes":             {Name: "process_disk_read_bytes_total", Type: "counter", Value: rowValue},
+	"process_write_bytes":            {Name: "process_disk_written_bytes_total", Type: "counter", Value: rowValue},
+	"process_read_ops":               {Name: "process_disk_reads_total", Type: "counter", Value: rowValue},
+	"process_write_ops":              {Name: "process_disk_writes_total", Type: "counter", Value: rowValue},
+	"process_read_bytes_per_sec":     {Name: "process_disk_read_bytes_per_second", Type: "gauge", Value: rowValue},
+	"process_write_bytes_per_sec":    {Name: "process_disk_written_bytes_per_second", Type: "gauge", Value: rowValue},
+	"disk_read_bytes":                {Name: "disk_read_bytes_total", Type: "counter", Value: rowValue},
+	"disk_write_bytes":               {Name: "disk_written_bytes_total", Type: "counter", Value: rowValue},
+	"disk_read_ops":                  {Name: "disk_reads_total", Type: "counter", Value: rowValue},
+	"disk_write_ops":                 {Name: "disk_writes_total", Type: "counter", Value: rowValue},
+	"disk_read_bytes_per_sec":        {Name: "disk_read_bytes_per_second", Type: "gauge", Value: rowValue},
+	"disk_write_bytes_per_sec":       {Name: "disk_written_bytes_per_second", Type: "gauge", Value: rowValue},
+	"disk_read_ops_per_sec":          {Name: "disk_reads_per_second", Type: "gauge", Value: rowValue},
+	"disk_write_ops_per_sec":         {Name: "disk_writes_per_second", Type: "gauge", Value: rowValue},
+	"disk_utilization_percent":       {Name: "disk_utilization_ratio", Type: "gauge", Value: percentToRatio},
+	"filesystem_total_bytes":         {Name: "filesystem_size_bytes", Type: "gauge", Value: rowValue},
+	"filesystem_used_bytes":          {Name: "filesystem_used_bytes", Type: "gauge", Value: rowValue},
+	"filesystem_used_percent":        {Name: "filesystem_used_ratio", Type: "gauge", Value: percentToRatio},
+	"filesystem_inodes_used_percent": {Name: "filesystem_inodes_used_ratio", Type: "gauge", Value: percentToRatio},
+
+	// Traced Functions
+	"function_calls":                {Name: "function_calls_total", Type: "counter", Value: rowValue},
+	"function_errors":               {Name: "function_errors_total", Type: "counter", Value: rowValue},
+	"function_panics":               {Name: "function_panics_total", Type: "counter", Value: rowValue},
+	"function_execution_time_min":   {Name: "function_execution_time_min_seconds", Type: "gauge", Value: msToSeconds},
+	"function_execution_time_max":   {Name: "function_execution_time_max_seconds", Type: "gauge", Value: msToSeconds},
+	"function_execution_time_mean":  {Name: "function_execution_time_mean_seconds", Type: "gauge", Value: msToSeconds},
+	"function_execution_time_p95":   summaryMetric("function_execution_time_seconds", "Execution time of a traced function in seconds", "0.95", msToSeconds),
+	"function_execution_time_p99":   summaryMetric("function_execution_time_seconds", "Execution time of a traced function in seconds", "0.99", msToSeconds),
+	"function_allocated_bytes_min":  {Name: "function_allocated_min_bytes", Type: "gauge", Value: rowValue},
+	"function_allocated_bytes_max":  {Name: "function_allocated_max_bytes", Type: "gauge", Value: rowValue},
+	"function_allocated_bytes_mean": {Name: "function_allocated_mean_bytes", Type: "gauge", Value: rowValue},
+	"function_allocated_bytes_p95":  summaryMetric("function_allocated_bytes", "Bytes allocated by a call of a traced function", "0.95", rowValue),
+	"function_allocated_bytes_p99":  summaryMetric("function_allocated_bytes", "Bytes allocated by a call of a traced function", "0.99", rowValue),
+
+	// Runtime
+	"runtime_heap_goal":            {Name: "runtime_heap_goal_bytes", Type: "gauge", Value: rowValue},
+	"runtime_heap_live":            {Name: "runtime_heap_live_bytes", Type: "gauge", Value: rowValue},
+	"runtime_memory_limit":         {Name: "runtime_memory_limit_bytes", Type: "gauge", Value: rowValue},
+	"runtime_gc_cycles":            {Name: "runtime_gc_cycles_total", Type: "counter", Value: rowValue},
+	"runtime_mutex_wait":           {Name: "runtime_mutex_wait_seconds_total", Type: "counter", Value: msToSeconds},
+	"runtime_sched_latency_count":  histogramMetric("runtime_sched_latency_seconds", schedLatencyHelp, "_count", rowValue),
+	"runtime_sched_latency_bucket": histogramMetric("runtime_sched_latency_seconds", schedLatencyHelp, "_bucket", rowValue),
+	"runtime_sched_latency_p50":    summaryMetric("runtime_sched_latency_interval_seconds", schedLatencyIntervalHelp, "0.5", msToSeconds),
+	"runtime_sched_latency_p90":    summaryMetric("runtime_sched_latency_interval_seconds", schedLatencyIntervalHelp, "0.9", msToSeconds),
+	"runtime_sched_latency_p99":    summaryMetric("runtime_sched_latency_interval_seconds", schedLatencyIntervalHelp, "0.99", msToSeconds),
+	"runtime_gc_pause_count":       histogramMetric("runtime_gc_pause_seconds", gcPauseHelp, "_count", rowValue),
+	"runtime_gc_pause_bucket":      histogramMetric("runtime_gc_pause_seconds", gcPauseHelp, "_bucket", rowValue),
+	"runtime_gc_pause_p50":         summaryMetric("runtime_gc_pause_interval_seconds", gcPauseIntervalHelp, "0.5", msToSeconds),
+	"runtime_gc_pause_p90":         summaryMetric("runtime_gc_pause_interval_seconds", gcPauseIntervalHelp, "0.9", msToSeconds),
+	"runtime_gc_pause_p99":         summaryMetric("runtime_gc_pause_interval_seconds", gcPauseIntervalHelp, "0.99", msToSeconds),
+
+	// Health
+	"service_health_percent": {Name: "service_health_ratio", Type: "gauge", Value: percentToRatio},
+	"system_health_percent":  {Name: "system_health_ratio", Type: "gauge", Value: percentToRatio},
+}
+
+// GetPrometheusMetrics returns every collected statistic in the Prometheus text exposition format
+func GetPrometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	stats := core.GetServiceStats()
+	labels := append(timeseries.DefaultLabels(), timeseries.InstanceLabels()...) // The scrapes are not stored by monigo
+	rows := timeseries.GenerateServiceMetricsRows(&stats, labels, time.Now().Unix())
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(FormatPrometheusMetrics(rows, &stats)))
+}
+
+// FormatPrometheusMetrics formats the rows in the Prometheus text exposition format.
+// Rows without a known representation are exposed as untyped metrics with their stored value.
+func FormatPrometheusMetrics(rows []tstorage.Row, stats *models.ServiceStats) string {
+	customMetrics := customPromMetrics(stats)
+	families := make(map[string]*promFamily)
+	for _, row := range rows {
+		metric, ok := prometheusMetrics[row.Metric]
+		if !ok {
+			metric, ok = customMetrics[row.Metric]
+		}
+		if !ok {
+			metric = promMetric{Name: row.Metric, Type: "untyped", Value: rowValue}
+		}
+
+		if metric.Hidden {
+			continue
+		}
+
+		name := prometheusNamespace + "_" + sanitizePromName(metric.Name)
+		family, ok := families[name]
+		if !ok {
+			help, ok := fieldDescription[row.Metric]
+			if metric.Help != "" {
+				help = metric.Help
+			} else if !ok {
+				help = row.Metric
+			}
+			family = &promFamily{help: help, metricType: metric.Type, samples: make(map[string]promSample)}
+			families[name] = family
+		}
+
+		sample := promSample{suffix: metric.Suffix, value: metric.Value(row, stats), suffixRank: promSuffixRank(metric.Suffix)}
+		var seriesLabels []tstorage.Label
+		for _, label := range append(append([]tstorage.Label{}, row.Labels...), metric.ExtraLabels...) {
+			if label.Name == "le" || label.Name == "quantile" {
+				sample.bound, _ = strconv.ParseFloat(label.Value, 64)
+				continue
+			}
+			seriesLabels = append(seriesLabels, label)
+		}
+		sample.seriesKey = formatPromLabels(seriesLabels)
+		sample.labels = formatPromLabels(append(append([]tstorage.Label{}, row.Labels...), metric.ExtraLabels...))
+		family.samples[sample.suffix+sample.labels] = sample // Later rows with the same labels win
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		family := families[name]
+		fmt.Fprintf(&sb, "# HELP %s %s\n", name, escapePromHelp(family.help))
+		fmt.Fprintf(&sb, "# TYPE %s %s\n", name, family.metricType)
+
+		// The samples of a series are written together: the quantiles or buckets in increasing order, then _sum and _count
+		samples := make([]promSample, 0, len(family.samples))
+		for _, sample := range family.samples {
+			samples = append(samples, sample)
+		}
+		sort.Slice(samples, func(i, j int) bool {
+			a, b := samples[i], samples[j]
+			if a.seriesKey != b.seriesKey {
+				return a.seriesKey < b.seriesKey
+			}
+			if a.suffixRank != b.suffixRank {
+				return a.suffixRank < b.suffixRank
+			}
+			return a.bound < b.bound
+		})
+
+		for _, sample := range samples {
+			fmt.Fprintf(&sb, "%s%s%s %s\n", name, sample.suffix, sample.labels, formatPromValue(sample.value))
+		}
+	}
+
+	return sb.String()
+}
+
+// promSuffixRank returns the position of the samples with the suffix within a histogram or summary series.
+func promSuffixRank(suffix string) int {
+	switch suffix {
+	case "_sum":
+		return 1
+	case "_count":
+		return 2
+	default:
+		return 0
+	}
+}
+
+// customPromMetrics returns the Prometheus representation of the rows stored for the custom metrics.
+func customPromMetrics(stats *models.ServiceStats) map[string]promMetric {
+	metrics := make(map[string]promMetric)
+	for _, m := range stats.CustomMetrics {
+		help := common.DefaultIfEmpty(m.Description, m.Name)
+		switch m.Type {
+		case core.HistogramMetric:
+			for _, suffix := range []string{"_count", "_sum", "_bucket"} {
+				metrics[m.Name+suffix] = histogramMetric(m.Name, help, suffix, rowValue)
+			}
+			// The percentiles are estimated from the buckets, histogram_quantile gives them from the exposed buckets
+			for _, suffix := range []string{"_p50", "_p90", "_p99"} {
+				metrics[m.Name+suffix] = promMetric{Name: m.Name, Hidden: true}
+			}
+		default:
+			metrics[m.Name] = promMetric{Name: m.Name, Help: help, Type: m.Type, Value: rowValue}
+		}
+	}
+	return metrics
+}
+
+// formatPromLabels formats the labels as {name="value",...}, sorted by name.
+func formatPromLabels(labels []tstorage.Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	pairs := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label.Name == "" || label.Value == "" {
+			continue
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", sanitizePromName(label.Name), escapePromLabelValue(label.Value)))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatPromValue formats the value the way Prometheus expects it, including NaN and infinities.
+func formatPromValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// sanitizePromName replaces the characters not allowed in metric and label names with underscores.
+func sanitizePromName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// escapePromHelp escapes the backslashes and new lines of the HELP text.
+func escapePromHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// escapePromLabelValue escapes the backslashes, double quotes and new lines of the label value.
+func escapePromLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/iyashjayesh/monigo/core"
+	"github.com/iyashjayesh/monigo/models"
+	"github.com/nakabonne/tstorage"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// exampleRows returns rows of gauges, counters, summaries and histograms, in no particular order.
+func exampleRows() []tstorage.Row {
+	host := []tstorage.Label{{Name: "host", Value: "web-1"}}
+	with := func(labels ...tstorage.Label) []tstorage.Label {
+		return append(append([]tstorage.Label{}, host...), labels...)
+	}
+	row := func(metric string, value float64, labels []tstorage.Label) tstorage.Row {
+		return tstorage.Row{Metric: metric, DataPoint: tstorage.DataPoint{Timestamp: 1, Value: value}, Labels: labels}
+	}
+	route := tstorage.Label{Name: "route", Value: `/files/"{name}"\`}
+
+	return []tstorage.Row{
+		row("goroutines", 12, host),
+		row("http_requests", 3, with(route, tstorage.Label{Name: "method", Value: "GET"}, tstorage.Label{Name: "status_class", Value: "2xx"})),
+		row("http_request_latency_p99", 120, with(route, tstorage.Label{Name: "method", Value: "GET"})),
+		row("http_request_latency_p50", 20, with(route, tstorage.Label{Name: "method", Value: "GET"})),
+		row("http_request_latency_p90", 80, with(route, tstorage.Label{Name: "method", Value: "GET"})),
+
+		// Custom histogram, the stored percentiles are not exposed
+		row("custom_order_value_bucket", 5, with(tstorage.Label{Name: "le", Value: "+Inf"})),
+		row("custom_order_value_bucket", 1, with(tstorage.Label{Name: "le", Value: "10"})),
+		row("custom_order_value_count", 5, host),
+		row("custom_order_value_bucket", 4, with(tstorage.Label{Name: "le", Value: "100"})),
+		row("custom_order_value_sum", 260.5, host),
+		row("custom_order_value_p50", 55, host),
+
+		// Runtime histogram and the percentiles of the last interval
+		row("runtime_gc_pause_bucket", 2, with(tstorage.Label{Name: "le", Value: "0.0001"})),
+		row("runtime_gc_pause_bucket", 7, with(tstorage.Label{Name: "le", Value: "+Inf"})),
+		row("runtime_gc_pause_bucket", 6, with(tstorage.Label{Name: "le", Value: "0.001"})),
+		row("runtime_gc_pause_count", 7, host),
+		row("runtime_gc_pause_p50", 0.2, host),
+
+		// Unknown row, its name and label names are sanitized
+		row("queue.depth-max", 4, with(tstorage.Label{Name: "queue-name", Value: "jobs"})),
+	}
+}
+
+func exampleStats() *models.ServiceStats {
+	return &models.ServiceStats{CustomMetrics: []models.CustomMetric{{
+		Name:        "custom_order_value",
+		Description: "Value of the orders\nin euros, \\ excluded",
+		Type:        core.HistogramMetric,
+	}}}
+}
+
+func TestFormatPrometheusMetricsGolden(t *testing.T) {
+	got := FormatPrometheusMetrics(exampleRows(), exampleStats())
+
+	golden := filepath.Join("testdata", "prometheus.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("FormatPrometheusMetrics() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatPrometheusMetricsParses(t *testing.T) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(FormatPrometheusMetrics(exampleRows(), exampleStats())))
+	if err != nil {
+		t.Fatalf("the exposition does not parse: %v", err)
+	}
+
+	wantTypes := map[string]dto.MetricType{
+		"monigo_goroutines":                        dto.MetricType_GAUGE,
+		"monigo_http_requests_total":               dto.MetricType_COUNTER,
+		"monigo_http_request_latency_seconds":      dto.MetricType_SUMMARY,
+		"monigo_custom_order_value":                dto.MetricType_HISTOGRAM,
+		"monigo_runtime_gc_pause_seconds":          dto.MetricType_HISTOGRAM,
+		"monigo_runtime_gc_pause_interval_seconds": dto.MetricType_SUMMARY,
+		"monigo_queue_depth_max":                   dto.MetricType_UNTYPED,
+	}
+	if len(families) != len(wantTypes) {
+		t.Errorf("got %d families, want %d", len(families), len(wantTypes))
+	}
+	for name, want := range wantTypes {
+		family, ok := families[name]
+		if !ok {
+			t.Errorf("family %s is missing", name)
+			continue
+		}
+		if family.GetType() != want {
+			t.Errorf("type of %s = %v, want %v", name, family.GetType(), want)
+		}
+	}
+
+	histogram := families["monigo_custom_order_value"].GetMetric()[0].GetHistogram()
+	if histogram.GetSampleCount() != 5 || histogram.GetSampleSum() != 260.5 {
+		t.Errorf("histogram count and sum = %d, %v, want 5, 260.5", histogram.GetSampleCount(), histogram.GetSampleSum())
+	}
+	var bounds []float64
+	var previous uint64
+	for _, bucket := range histogram.GetBucket() {
+		bounds = append(bounds, bucket.GetUpperBound())
+		if bucket.GetCumulativeCount() < previous {
+			t.Errorf("bucket %v is not cumulative", bucket.GetUpperBound())
+		}
+		previous = bucket.GetCumulativeCount()
+	}
+	if len(bounds) != 3 || bounds[0] != 10 || bounds[1] != 100 {
+		t.Errorf("bucket bounds = %v, want 10, 100 and +Inf", bounds)
+	}
+
+	summary := families["monigo_http_request_latency_seconds"].GetMetric()[0].GetSummary()
+	var quantiles []float64
+	for _, q := range summary.GetQuantile() {
+		quantiles = append(quantiles, q.GetQuantile(), q.GetValue())
+	}
+	if want := []float64{0.5, 0.02, 0.9, 0.08, 0.99, 0.12}; !slices.Equal(quantiles, want) {
+		t.Errorf("quantiles = %v, want %v", quantiles, want)
+	}
+}
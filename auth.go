@@ -0,0 +1,148 @@
+package monigo
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Access is the permission required to call a dashboard endpoint
+type Access int
+
+const (
+	AccessRead    Access = iota // Dashboard pages and read-only APIs
+	AccessControl               // APIs changing the state of the service, ex. capturing profiles
+)
+
+// Authorizer decides whether a request may access the dashboard and the monigo APIs
+type Authorizer interface {
+	Authorize(r *http.Request) bool
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface
+type AuthorizerFunc func(r *http.Request) bool
+
+// Authorize calls f(r)
+func (f AuthorizerFunc) Authorize(r *http.Request) bool {
+	return f(r)
+}
+
+// challenger is implemented by the authorizers asking the client for credentials on failure
+type challenger interface {
+	Challenge() string // Value of the WWW-Authenticate header
+}
+
+// basicAuth authorizes the requests carrying one of the configured username and password pairs
+type basicAuth struct {
+	credentials map[string][32]byte // Username to the SHA-256 of the password
+}
+
+// BasicAuth returns an Authorizer accepting HTTP basic auth with any of the username and password pairs
+func BasicAuth(credentials map[string]string) Authorizer {
+	a := &basicAuth{credentials: make(map[string][32]byte, len(credentials))}
+	for username, password := range credentials {
+		a.credentials[username] = sha256.Sum256([]byte(password))
+	}
+	return a
+}
+
+// Authorize checks the basic auth credentials of the request
+func (a *basicAuth) Authorize(r *http.Request) bool {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	expected, ok := a.credentials[username]
+	if !ok {
+		return false
+	}
+
+	actual := sha256.Sum256([]byte(password)) // Comparing fixed size hashes in constant time
+	return subtle.ConstantTimeCompare(actual[:], expected[:]) == 1
+}
+
+// Challenge asks the browser to prompt for the credentials
+func (a *basicAuth) Challenge() string {
+	return `Basic realm="MoniGo", charset="UTF-8"`
+}
+
+// bearerToken authorizes the requests carrying one of the configured static tokens
+type bearerToken struct {
+	tokens [][32]byte // SHA-256 of the tokens
+}
+
+// BearerToken returns an Authorizer accepting an "Authorization: Bearer <token>" header with any of the tokens
+func BearerToken(tokens ...string) Authorizer {
+	a := &bearerToken{}
+	for _, token := range tokens {
+		a.tokens = append(a.tokens, sha256.Sum256([]byte(token)))
+	}
+	return a
+}
+
+// Authorize checks the bearer token of the request
+func (a *bearerToken) Authorize(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return false
+	}
+
+	actual := sha256.Sum256([]byte(strings.TrimSpace(header[len("Bearer "):])))
+	authorized := false
+	for _, expected := range a.tokens {
+		if subtle.ConstantTimeCompare(actual[:], expected[:]) == 1 {
+			authorized = true
+		}
+	}
+	return authorized
+}
+
+// anyOf authorizes the requests accepted by any of the authorizers
+type anyOf []Authorizer
+
+// AnyOf returns an Authorizer accepting the requests accepted by any of the authorizers, ex. basic auth for
+// the browser and a bearer token for the scripts
+func AnyOf(authorizers ...Authorizer) Authorizer {
+	return anyOf(authorizers)
+}
+
+// Authorize checks the request against every authorizer
+func (a anyOf) Authorize(r *http.Request) bool {
+	for _, authorizer := range a {
+		if authorizer != nil && authorizer.Authorize(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Challenge returns the first challenge of the authorizers
+func (a anyOf) Challenge() string {
+	for _, authorizer := range a {
+		if c, ok := authorizer.(challenger); ok {
+			return c.Challenge()
+		}
+	}
+	return ""
+}
+
+// requireAuthorization wraps the handler so it is only served to the requests accepted by the authorizer.
+// A nil authorizer serves every request.
+func requireAuthorization(authorizer Authorizer, next http.Handler) http.Handler {
+	if authorizer == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorizer.Authorize(r) {
+			if c, ok := authorizer.(challenger); ok {
+				w.Header().Set("WWW-Authenticate", c.Challenge())
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
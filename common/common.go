@@ -22,9 +22,11 @@ const monigoFolder string = "monigo"
 var (
 	serviceInfo      models.ServiceInfo
 	rententionPeriod string
+	persistData      bool
 )
 
-// GetBasePath returns the base path for storage.
+// GetBasePath returns the base path for storage, the monigo folder under the working directory.
+// The folder is created by the first file written under it.
 func GetBasePath() string {
 	var path string
 	appPath, _ := os.Getwd()
@@ -34,10 +36,6 @@ func GetBasePath() string {
 		path = fmt.Sprintf("%s/%s", appPath, monigoFolder)
 	}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		os.Mkdir(path, os.ModePerm)
-	}
-
 	return path
 }
 
@@ -119,16 +117,54 @@ func ConstructJsonFieldDescription() map[string]string {
 		"go_version": "Go version is the version the service is running on",
 		"process_id": "Process ID is the process id of the monigo service",
 		"goroutines": "Goroutines is the number of goroutines running in the service",
-		"requests_count": "Requests Count is the number of requests served by the service",
+		"request_count": "Request Count is the number of requests served by the service",
+		"total_duration_took_by_request": "Total Duration Took by Request is the total time in milliseconds spent serving requests",
+		"sampler_collection_duration": "Sampler Collection Duration is the time in milliseconds taken to collect the service statistics",
+		"sampler_overhead_percent": "Sampler Overhead Percent is the share of the sampling interval spent collecting the service statistics",
+		"collector_errors_total": "Collector Errors Total is the number of failed collections per collector, the statistics of a failing collector are left empty",
+		"container_memory_limit": "Container Memory Limit is the memory limit of the cgroup in bytes, 0 when not limited",
+		"container_memory_usage": "Container Memory Usage is the memory used by the cgroup in bytes, including the page cache",
+		"container_memory_working_set": "Container Memory Working Set is the memory used by the cgroup in bytes without the inactive page cache",
+		"container_memory_usage_percent": "Container Memory Usage Percent is the working set of the cgroup memory limit",
+		"container_oom_events": "Container OOM Events is the number of times the cgroup memory limit was reached",
+		"container_oom_kills": "Container OOM Kills is the number of processes of the cgroup killed by the OOM killer",
+		"container_cpu_limit_cores": "Container CPU Limit Cores is the CPU quota of the cgroup in cores, 0 when not limited",
+		"container_cpu_usage_cores": "Container CPU Usage Cores is the number of cores used by the cgroup",
+		"container_cpu_usage_percent": "Container CPU Usage Percent is the usage of the cgroup CPU limit",
+		"container_cpu_throttled_periods": "Container CPU Throttled Periods is the number of periods the cgroup was throttled in",
+		"container_cpu_throttled_seconds": "Container CPU Throttled Seconds is the time the cgroup was throttled for",
+		"http_requests": "HTTP Requests is the number of requests served per route, method and status class",
+		"http_request_latency_p50": "HTTP Request Latency P50 is the median latency in milliseconds of a route",
+		"http_request_latency_p90": "HTTP Request Latency P90 is the 90th percentile latency in milliseconds of a route",
+		"http_request_latency_p99": "HTTP Request Latency P99 is the 99th percentile latency in milliseconds of a route",
 		"service_cpu_load": "Service CPU Load is the CPU usage of the service",
 		"system_cpu_load": "System CPU Load is the CPU usage of the system",
 		"total_cpu_load": "Total CPU Load is the CPU usage of the system and the service",
 		"service_memory_load": "Service Memory[RAM] Load is the memory usage of the service",
 		"system_memory_load": "System Memory[RAM] Load is the memory usage of the system",
 		"total_memory_load": "Total Memory[RAM] Load is the memory usage of the system and the service",
-		"service_disk_load": "Service Disk Load is the disk usage of the service",
-		"system_disk_load": "System Disk Load is the disk usage of the system",
-		"total_disk_load": "Total Disk Load is the disk usage of the system and the service",
+		"service_disk_load": "Service Disk Load is the share of the disk throughput done by the service",
+		"system_disk_load": "System Disk Load is the utilization of the busiest disk of the system",
+		"total_disk_load": "Total Disk Load is the used space of the volume holding the monigo data",
+		"process_read_bytes": "Process Read Bytes is the number of bytes the service read from the disks",
+		"process_write_bytes": "Process Write Bytes is the number of bytes the service wrote to the disks",
+		"process_read_ops": "Process Read Ops is the number of read calls made by the service",
+		"process_write_ops": "Process Write Ops is the number of write calls made by the service",
+		"process_read_bytes_per_sec": "Process Read Bytes per Second is the rate the service reads from the disks at",
+		"process_write_bytes_per_sec": "Process Write Bytes per Second is the rate the service writes to the disks at",
+		"disk_read_bytes": "Disk Read Bytes is the number of bytes read from a disk",
+		"disk_write_bytes": "Disk Write Bytes is the number of bytes written to a disk",
+		"disk_read_ops": "Disk Read Ops is the number of reads completed by a disk",
+		"disk_write_ops": "Disk Write Ops is the number of writes completed by a disk",
+		"disk_read_bytes_per_sec": "Disk Read Bytes per Second is the rate a disk is read from at",
+		"disk_write_bytes_per_sec": "Disk Write Bytes per Second is the rate a disk is written to at",
+		"disk_read_ops_per_sec": "Disk Read Ops per Second is the number of reads per second completed by a disk",
+		"disk_write_ops_per_sec": "Disk Write Ops per Second is the number of writes per second completed by a disk",
+		"disk_utilization_percent": "Disk Utilization Percent is the percentage of the time a disk was busy",
+		"filesystem_total_bytes": "Filesystem Total Bytes is the size of the volume holding the monigo data",
+		"filesystem_used_bytes": "Filesystem Used Bytes is the used space of the volume holding the monigo data",
+		"filesystem_used_percent": "Filesystem Used Percent is the used space of the volume holding the monigo data in percent",
+		"filesystem_inodes_used_percent": "Filesystem Inodes Used Percent is the used inodes of the volume holding the monigo data in percent",
 		"overall_load_of_service": "Overall Load of Service is the overall load service is under on the system",
 		"total_cores": "Total Cores is the number of cores the system has",
 		"total_logical_cores": "Total Logical Cores is the number of logical cores the system has",
@@ -140,6 +176,83 @@ func ConstructJsonFieldDescription() map[string]string {
 		"memory_used_by_system": "Memory[RAM] Used by System is the memory the system is using",
 		"memory_used_by_service": "Memory[RAM] Used by Service is the memory the service is using",
 		"available_memory": "Available Memory[RAM] is the memory available on the system",
+		"gc_pause_duration": "GC Pause Duration is the cumulative time the service spent in GC stop-the-world pauses",
+		"stack_memory_usage": "Stack Memory Usage is the memory used by the goroutine stacks of the service",
+		"heap_alloc_by_service": "Heap Alloc by Service is the heap memory allocated by the service",
+		"heap_alloc_by_system": "Heap Alloc by System is the heap memory obtained from the OS",
+		"total_alloc_by_service": "Total Alloc by Service is the cumulative heap memory allocated by the service",
+		"total_memory_by_os": "Total Memory by OS is the total memory obtained from the OS",
+		"bytes_sent": "Bytes Sent is the number of bytes sent over the network",
+		"bytes_received": "Bytes Received is the number of bytes received over the network",
+		"function_calls": "Function Calls is the number of calls of a traced function",
+		"function_errors": "Function Errors is the number of calls of a traced function that returned an error or panicked",
+		"function_panics": "Function Panics is the number of calls of a traced function that panicked",
+		"function_execution_time_min": "Function Execution Time Min is the shortest call of a traced function in milliseconds",
+		"function_execution_time_max": "Function Execution Time Max is the longest call of a traced function in milliseconds",
+		"function_execution_time_mean": "Function Execution Time Mean is the mean call duration of a traced function in milliseconds",
+		"function_execution_time_p95": "Function Execution Time P95 is the 95th percentile call duration of a traced function in milliseconds",
+		"function_execution_time_p99": "Function Execution Time P99 is the 99th percentile call duration of a traced function in milliseconds",
+		"function_allocated_bytes_min": "Function Allocated Bytes Min is the fewest bytes allocated by a call of a traced function",
+		"function_allocated_bytes_max": "Function Allocated Bytes Max is the most bytes allocated by a call of a traced function",
+		"function_allocated_bytes_mean": "Function Allocated Bytes Mean is the mean bytes allocated by a call of a traced function",
+		"function_allocated_bytes_p95": "Function Allocated Bytes P95 is the 95th percentile of the bytes allocated by a call of a traced function",
+		"function_allocated_bytes_p99": "Function Allocated Bytes P99 is the 99th percentile of the bytes allocated by a call of a traced function",
+		"runtime_heap_goal": "Runtime Heap Goal is the heap size in bytes the GC aims to finish the cycle at",
+		"runtime_heap_live": "Runtime Heap Live is the heap in bytes marked live by the previous GC cycle",
+		"runtime_memory_limit": "Runtime Memory Limit is the soft memory limit of the runtime in bytes, 0 when not set",
+		"runtime_gc_cycles": "Runtime GC Cycles is the number of completed GC cycles",
+		"runtime_mutex_wait": "Runtime Mutex Wait is the time in milliseconds goroutines spent blocked on a mutex",
+		"runtime_sched_latency_count": "Runtime Scheduler Latency Count is the number of times goroutines were scheduled",
+		"runtime_sched_latency_bucket": "Runtime Scheduler Latency Bucket is the number of times goroutines waited to be scheduled for at most the upper bound",
+		"runtime_sched_latency_p50": "Runtime Scheduler Latency P50 is the median time in milliseconds goroutines waited to be scheduled",
+		"runtime_sched_latency_p90": "Runtime Scheduler Latency P90 is the 90th percentile time in milliseconds goroutines waited to be scheduled",
+		"runtime_sched_latency_p99": "Runtime Scheduler Latency P99 is the 99th percentile time in milliseconds goroutines waited to be scheduled",
+		"runtime_gc_pause_count": "Runtime GC Pause Count is the number of stop-the-world pauses of the GC",
+		"runtime_gc_pause_bucket": "Runtime GC Pause Bucket is the number of GC pauses that lasted at most the upper bound",
+		"runtime_gc_pause_p50": "Runtime GC Pause P50 is the median GC pause in milliseconds",
+		"runtime_gc_pause_p90": "Runtime GC Pause P90 is the 90th percentile GC pause in milliseconds",
+		"runtime_gc_pause_p99": "Runtime GC Pause P99 is the 99th percentile GC pause in milliseconds",
+		"bytes_sent_per_sec": "Bytes Sent per Second is the rate bytes are sent over the network at",
+		"bytes_received_per_sec": "Bytes Received per Second is the rate bytes are received over the network at",
+		"network_bytes_sent": "Network Bytes Sent is the number of bytes sent by an interface",
+		"network_bytes_received": "Network Bytes Received is the number of bytes received by an interface",
+		"network_packets_sent": "Network Packets Sent is the number of packets sent by an interface",
+		"network_packets_received": "Network Packets Received is the number of packets received by an interface",
+		"network_errors_in": "Network Errors In is the number of errors while receiving on an interface",
+		"network_errors_out": "Network Errors Out is the number of errors while sending on an interface",
+		"network_drops_in": "Network Drops In is the number of incoming packets dropped by an interface",
+		"network_drops_out": "Network Drops Out is the number of outgoing packets dropped by an interface",
+		"network_bytes_sent_per_sec": "Network Bytes Sent per Second is the rate an interface sends bytes at",
+		"network_bytes_received_per_sec": "Network Bytes Received per Second is the rate an interface receives bytes at",
+		"service_health_percent": "Service Health Percent is the overall health of the service",
+		"system_health_percent": "System Health Percent is the overall health of the system",
+		"alloc": "Alloc is the memory of allocated heap objects",
+		"total_alloc": "Total Alloc is the cumulative memory allocated for heap objects",
+		"sys": "Sys is the total memory obtained from the OS",
+		"lookups": "Lookups is the number of pointer lookups performed by the runtime",
+		"mallocs": "Mallocs is the cumulative count of heap objects allocated",
+		"frees": "Frees is the cumulative count of heap objects freed",
+		"heap_alloc": "Heap Alloc is the memory of allocated heap objects",
+		"heap_sys": "Heap Sys is the heap memory obtained from the OS",
+		"heap_idle": "Heap Idle is the memory in idle (unused) spans",
+		"heap_inuse": "Heap Inuse is the memory in in-use spans",
+		"heap_released": "Heap Released is the physical memory returned to the OS",
+		"heap_objects": "Heap Objects is the number of allocated heap objects",
+		"stack_inuse": "Stack Inuse is the memory in stack spans",
+		"stack_sys": "Stack Sys is the stack memory obtained from the OS",
+		"m_span_inuse": "MSpan Inuse is the memory of allocated mspan structures",
+		"m_span_sys": "MSpan Sys is the memory obtained from the OS for mspan structures",
+		"m_cache_inuse": "MCache Inuse is the memory of allocated mcache structures",
+		"m_cache_sys": "MCache Sys is the memory obtained from the OS for mcache structures",
+		"buck_hash_sys": "Buck Hash Sys is the memory in profiling bucket hash tables",
+		"gc_sys": "GC Sys is the memory in garbage collection metadata",
+		"other_sys": "Other Sys is the memory in miscellaneous off-heap runtime allocations",
+		"next_gc": "Next GC is the target heap size of the next GC cycle",
+		"last_gc": "Last GC is the time the last garbage collection finished",
+		"pause_total_ns": "Pause Total is the cumulative time spent in GC stop-the-world pauses",
+		"num_gc": "Num GC is the number of completed GC cycles",
+		"num_forced_gc": "Num Forced GC is the number of GC cycles forced by the application",
+		"gc_cpu_fraction": "GC CPU Fraction is the fraction of the available CPU time used by the GC",
 		"uptime": "Uptime is the time the service has been running",
 		"timestamp": "Timestamp is the time the data was collected"
 	}`
@@ -254,6 +367,20 @@ func GetServiceStartTime() time.Time {
 	return serviceInfo.ServiceStartTime
 }
 
+// SetDataPersistence sets whether the stored data is kept across restarts.
+func SetDataPersistence(persist bool) {
+	persistData = persist
+}
+
+// GetDataStartTime returns the time of the oldest data that can be stored, the service start time
+// unless the data is kept across restarts for the retention period.
+func GetDataStartTime() time.Time {
+	if persistData {
+		return time.Now().Add(-GetDataRetentionPeriod())
+	}
+	return serviceInfo.ServiceStartTime
+}
+
 // parseDuration parses the duration string.
 func parseDuration(input string) (time.Duration, error) {
 	if strings.HasSuffix(input, "d") {
@@ -341,6 +468,32 @@ func ConvertToMB(value string) (float64, error) {
 	}
 }
 
+// ConvertReadableSizeToBytes converts a human-readable size ex. "1.20 GB" back to bytes.
+func ConvertReadableSizeToBytes(value string) (float64, error) {
+	var size float64
+	var unit string
+	if _, err := fmt.Sscanf(strings.TrimSpace(value), "%f %s", &size, &unit); err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", value, err)
+	}
+
+	switch strings.ToUpper(unit) {
+	case "B", "BYTES":
+		return size, nil
+	case "KB":
+		return size * 1024, nil
+	case "MB":
+		return size * math.Pow(1024, 2), nil
+	case "GB":
+		return size * math.Pow(1024, 3), nil
+	case "TB":
+		return size * math.Pow(1024, 4), nil
+	case "PB":
+		return size * math.Pow(1024, 5), nil
+	default:
+		return 0, fmt.Errorf("unsupported memory unit: %s", unit)
+	}
+}
+
 // Cache is the struct to store the cache data
 type Cache struct {
 	Data map[string]time.Time
@@ -354,6 +507,9 @@ func (c *Cache) SaveToFile(filename string) error {
 	}
 
 	base64Data := base64.StdEncoding.EncodeToString(jsonData)
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return err
+	}
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -1,67 +1,68 @@
 package common
 
 import (
-	"log"
+	"fmt"
 	"os"
 	"strconv"
-	"time"
+	"sync"
 
-	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/mem"
 	"github.com/shirou/gopsutil/process"
 )
 
-// GetCPULoad calculates the CPU load for the service, system, and total.
-func GetCPULoad() (serviceCPU, systemCPU, totalCPU string) {
-
-	proc := GetProcessObject()            // Getting process details
-	serviceCPUF, err := proc.CPUPercent() // 	Measure CPU percent for the current process
-	if err != nil {
-		log.Panicf("[MoniGo] Error fetching CPU load for the service: %v\n", err)
-	}
-	serviceCPU = ParseFloat64ToString(serviceCPUF) + "%" // This is the service CPU usage percentage.
-
-	cpuPercents, err := cpu.Percent(time.Second, false) // Get total system CPU percentage
-	if err != nil {
-		log.Panicf("[MoniGo] Error fetching CPU load for the system: %v\n", err)
-	}
-	if len(cpuPercents) > 0 {
-		systemCPU = ParseFloat64ToString(cpuPercents[0]-serviceCPUF) + "%" // This is the system CPU usage percentage.
-	}
+var (
+	procMu sync.Mutex       // Guards the process object
+	proc   *process.Process // Process object of the service, created on the first successful call
+)
 
-	totalCPU = ParseFloat64ToString(serviceCPUF+cpuPercents[0]) + "%" // This is the total CPU usage percentage.
+// GetCPULoad formats the CPU load for the service, system, and total from the CPU usage percentages of the service and the system.
+func GetCPULoad(serviceCPUF, systemCPUF float64) (serviceCPU, systemCPU, totalCPU string) {
+	serviceCPU = ParseFloat64ToString(serviceCPUF) + "%"           // This is the service CPU usage percentage.
+	systemCPU = ParseFloat64ToString(systemCPUF-serviceCPUF) + "%" // This is the system CPU usage percentage.
+	totalCPU = ParseFloat64ToString(serviceCPUF+systemCPUF) + "%"  // This is the total CPU usage percentage.
 	return serviceCPU, systemCPU, totalCPU
 }
 
 // GetMemoryLoad calculates the memory load for the service, system, and total.
-func GetMemoryLoad() (serviceMem, systemMem, totalMem string) {
+func GetMemoryLoad() (serviceMem, systemMem, totalMem string, err error) {
 	// Get system memory statistics
 	vmStat, err := mem.VirtualMemory()
 	if err != nil {
-		log.Panicf("[MoniGo] Error fetching memory load for the system: %v\n", err)
+		return "", "", "", fmt.Errorf("error fetching memory load for the system: %w", err)
 	}
 	systemMem = ParseFloat64ToString(vmStat.UsedPercent) + "%"          // Calculate system memory as a percentage of total memory
 	totalMem = ParseFloat64ToString(ParseUint64ToFloat64(vmStat.Total)) // Total memory in bytes Total amount of RAM on this system
 
-	proc := GetProcessObject()
+	proc, err := GetProcessObject()
+	if err != nil {
+		return "", "", "", err
+	}
 	memInfo, err := proc.MemoryInfo()
 	if err != nil {
-		log.Panicf("[MoniGo] Error fetching memory load for the service: %v\n", err)
+		return "", "", "", fmt.Errorf("error fetching memory load for the service: %w", err)
 	}
 
 	serviceMem = ParseFloat64ToString(float64(memInfo.RSS)/float64(vmStat.Total)*100) + "%" // Calculate service memory as a percentage of total memory
 
-	return serviceMem, systemMem, totalMem
+	return serviceMem, systemMem, totalMem, nil
 }
 
 // GetProcessDetails returns the process ID and process object.
-func GetProcessDetails() (int32, *process.Process) {
+// The process object is created once and reused, as it keeps the state of the process between calls.
+func GetProcessDetails() (int32, *process.Process, error) {
 	pid := GetProcessId()
-	proc, err := process.NewProcess(pid)
-	if err != nil {
-		log.Panicf("[MoniGo] Error fetching process details: %v\n", err)
+
+	procMu.Lock()
+	defer procMu.Unlock()
+
+	if proc == nil { // Retrying on the next call when the process details could not be fetched
+		p, err := process.NewProcess(pid)
+		if err != nil {
+			return pid, nil, fmt.Errorf("error fetching process details: %w", err)
+		}
+		proc = p
 	}
-	return pid, proc
+	return pid, proc, nil
 }
 
 // GetProcessId returns the process ID.
@@ -70,9 +71,9 @@ func GetProcessId() int32 {
 }
 
 // GetProcessObject returns the process object.
-func GetProcessObject() *process.Process {
-	_, proc := GetProcessDetails()
-	return proc
+func GetProcessObject() (*process.Process, error) {
+	_, proc, err := GetProcessDetails()
+	return proc, err
 }
 
 // ParseUint64ToFloat64 converts uint64 to float64.
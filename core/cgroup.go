@@ -0,0 +1,329 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const (
+	DefaultCgroupRoot = "/sys/fs/cgroup"    // Mount point of the cgroup filesystem
+	cgroupV1Unlimited = uint64(1) << 62     // cgroup v1 reports no memory limit as a page aligned value close to the max int64
+	procSelfCgroup    = "/proc/self/cgroup" // cgroups the service belongs to
+)
+
+var (
+	cgroupRoot       = DefaultCgroupRoot // Guarded by collectMu
+	lastContainerCPU containerCPUTimes   // CPU time read by the previous collection, guarded by collectMu
+)
+
+// containerCPUTimes holds the CPU time used by the container at a point in time.
+type containerCPUTimes struct {
+	at    time.Time
+	usage float64 // Seconds of CPU used by the container
+}
+
+// SetCgroupRoot sets the directory the cgroup limits and usage are read from, ex. a fixture directory or the host cgroup mounted in a sidecar.
+func SetCgroupRoot(root string) {
+	collectMu.Lock()
+	defer collectMu.Unlock()
+
+	if root == "" {
+		root = DefaultCgroupRoot
+	}
+	cgroupRoot = root
+}
+
+// ReadContainerStatistics reads the cgroup v1 or v2 limits and usage of the service under the root directory.
+// A zero CgroupVersion is returned when no cgroup is found, ex. outside Linux.
+func ReadContainerStatistics(root string) (models.ContainerStatistics, error) {
+	var stats models.ContainerStatistics
+
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		stats.CgroupVersion = 2
+		dir := cgroupDir(root, "")
+		memErr := readCgroupV2Memory(dir, &stats.Memory)
+		cpuErr := readCgroupV2CPU(dir, &stats.CPU)
+		return stats, errors.Join(memErr, cpuErr)
+	}
+
+	memoryRoot := filepath.Join(root, "memory")
+	cpuRoot := firstExistingDir(filepath.Join(root, "cpu,cpuacct"), filepath.Join(root, "cpu"), filepath.Join(root, "cpuacct"))
+	if !isDir(memoryRoot) && cpuRoot == "" {
+		return stats, nil // No cgroup filesystem
+	}
+
+	stats.CgroupVersion = 1
+	var memErr, cpuErr error
+	if isDir(memoryRoot) {
+		memErr = readCgroupV1Memory(cgroupDir(memoryRoot, "memory"), &stats.Memory)
+	}
+	if cpuRoot != "" {
+		cpuErr = readCgroupV1CPU(cgroupDir(cpuRoot, "cpu"), &stats.CPU)
+	}
+	return stats, errors.Join(memErr, cpuErr)
+}
+
+// readCgroupV2Memory reads memory.max, memory.current, memory.stat and memory.events of a cgroup v2 directory.
+func readCgroupV2Memory(dir string, memory *models.ContainerMemory) error {
+	limit, err := readCgroupValue(filepath.Join(dir, "memory.max"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	memory.LimitBytes = limit
+
+	if memory.UsageBytes, err = readCgroupValue(filepath.Join(dir, "memory.current")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	stat, err := readCgroupKeyValues(filepath.Join(dir, "memory.stat"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	memory.WorkingSetBytes = workingSet(memory.UsageBytes, stat["inactive_file"])
+
+	events, err := readCgroupKeyValues(filepath.Join(dir, "memory.events"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	memory.OOMEvents = events["oom"]
+	memory.OOMKills = events["oom_kill"]
+	return nil
+}
+
+// readCgroupV2CPU reads cpu.max and cpu.stat of a cgroup v2 directory.
+func readCgroupV2CPU(dir string, cpu *models.ContainerCPU) error {
+	content, err := os.ReadFile(filepath.Join(dir, "cpu.max"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading cpu.max: %w", err)
+	}
+	if fields := strings.Fields(string(content)); len(fields) == 2 && fields[0] != "max" { // "<quota> <period>" or "max <period>"
+		quota, quotaErr := strconv.ParseFloat(fields[0], 64)
+		period, periodErr := strconv.ParseFloat(fields[1], 64)
+		if quotaErr != nil || periodErr != nil || period == 0 {
+			return fmt.Errorf("invalid cpu.max: %q", strings.TrimSpace(string(content)))
+		}
+		cpu.LimitCores = quota / period
+	}
+
+	stat, err := readCgroupKeyValues(filepath.Join(dir, "cpu.stat"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	cpu.UsageSeconds = float64(stat["usage_usec"]) / 1e6
+	cpu.Periods = stat["nr_periods"]
+	cpu.ThrottledPeriods = stat["nr_throttled"]
+	cpu.ThrottledSeconds = float64(stat["throttled_usec"]) / 1e6
+	return nil
+}
+
+// readCgroupV1Memory reads the memory controller files of a cgroup v1 directory.
+func readCgroupV1Memory(dir string, memory *models.ContainerMemory) error {
+	limit, err := readCgroupValue(filepath.Join(dir, "memory.limit_in_bytes"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if limit < cgroupV1Unlimited {
+		memory.LimitBytes = limit
+	}
+
+	if memory.UsageBytes, err = readCgroupValue(filepath.Join(dir, "memory.usage_in_bytes")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	stat, err := readCgroupKeyValues(filepath.Join(dir, "memory.stat"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	memory.WorkingSetBytes = workingSet(memory.UsageBytes, stat["total_inactive_file"]) // Including the child cgroups, like the usage
+
+	oomControl, err := readCgroupKeyValues(filepath.Join(dir, "memory.oom_control"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	memory.OOMKills = oomControl["oom_kill"]
+
+	// cgroup v1 has no OOM event counter, the times the limit was hit are the closest
+	if memory.OOMEvents, err = readCgroupValue(filepath.Join(dir, "memory.failcnt")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// workingSet returns the memory of the cgroup that cannot be reclaimed, the usage without the inactive page cache,
+// like the working set the kubelet evicts and the OOM killer acts on.
+func workingSet(usage, inactiveFile uint64) uint64 {
+	if inactiveFile > usage {
+		return 0
+	}
+	return usage - inactiveFile
+}
+
+// readCgroupV1CPU reads the cpu and cpuacct controller files of a cgroup v1 directory.
+func readCgroupV1CPU(dir string, cpu *models.ContainerCPU) error {
+	quota, err := readCgroupInt(filepath.Join(dir, "cpu.cfs_quota_us"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	period, err := readCgroupInt(filepath.Join(dir, "cpu.cfs_period_us"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if quota > 0 && period > 0 { // A quota of -1 means no limit
+		cpu.LimitCores = float64(quota) / float64(period)
+	}
+
+	usage, err := readCgroupValue(filepath.Join(dir, "cpuacct.usage"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	cpu.UsageSeconds = float64(usage) / 1e9
+
+	stat, err := readCgroupKeyValues(filepath.Join(dir, "cpu.stat"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	cpu.Periods = stat["nr_periods"]
+	cpu.ThrottledPeriods = stat["nr_throttled"]
+	cpu.ThrottledSeconds = float64(stat["throttled_time"]) / 1e9
+	return nil
+}
+
+// cgroupDir returns the directory of the service cgroup under the root, the controller is empty for cgroup v2.
+// The root is used when the service cgroup is not visible under it, ex. inside a container with its own cgroup namespace.
+func cgroupDir(root, controller string) string {
+	file, err := os.Open(procSelfCgroup)
+	if err != nil {
+		return root
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3) // "<id>:<controllers>:<path>"
+		if len(parts) != 3 {
+			continue
+		}
+
+		matches := controller == "" && parts[0] == "0" && parts[1] == ""
+		for _, c := range strings.Split(parts[1], ",") {
+			matches = matches || (controller != "" && c == controller)
+		}
+		if !matches {
+			continue
+		}
+
+		if dir := filepath.Join(root, parts[2]); parts[2] != "/" && isDir(dir) {
+			return dir
+		}
+		return root
+	}
+	return root
+}
+
+// readCgroupValue reads a file holding a single unsigned value, "max" is returned as 0.
+func readCgroupValue(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	value := strings.TrimSpace(string(content))
+	if value == "max" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value in %s: %w", filepath.Base(path), err)
+	}
+	return parsed, nil
+}
+
+// readCgroupInt reads a file holding a single signed value.
+func readCgroupInt(path string) (int64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	parsed, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value in %s: %w", filepath.Base(path), err)
+	}
+	return parsed, nil
+}
+
+// readCgroupKeyValues reads a file holding a "<key> <value>" pair per line, ex. cpu.stat.
+func readCgroupKeyValues(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filepath.Base(path), err)
+	}
+	return values, nil
+}
+
+// firstExistingDir returns the first of the directories that exists, or an empty string.
+func firstExistingDir(dirs ...string) string {
+	for _, dir := range dirs {
+		if isDir(dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// isDir returns whether the path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// GetContainerStatistics reads the cgroup limits and usage of the service, measuring the CPU usage since the previous collection.
+// It must be called with collectMu held.
+func GetContainerStatistics() (models.ContainerStatistics, error) {
+	stats, err := ReadContainerStatistics(cgroupRoot)
+	if stats.CgroupVersion == 0 {
+		return stats, err
+	}
+
+	if stats.Memory.LimitBytes > 0 {
+		stats.Memory.UsagePercent = float64(stats.Memory.WorkingSetBytes) / float64(stats.Memory.LimitBytes) * 100 // The page cache is reclaimed before reaching the limit
+	}
+	if stats.CPU.Periods > 0 {
+		stats.CPU.ThrottledPercent = float64(stats.CPU.ThrottledPeriods) / float64(stats.CPU.Periods) * 100
+	}
+
+	now := time.Now()
+	if previous := lastContainerCPU; !previous.at.IsZero() && stats.CPU.UsageSeconds >= previous.usage {
+		if elapsed := now.Sub(previous.at).Seconds(); elapsed > 0 {
+			stats.CPU.UsageCores = (stats.CPU.UsageSeconds - previous.usage) / elapsed
+		}
+	}
+	lastContainerCPU = containerCPUTimes{at: now, usage: stats.CPU.UsageSeconds}
+
+	if stats.CPU.LimitCores > 0 {
+		stats.CPU.UsagePercent = stats.CPU.UsageCores / stats.CPU.LimitCores * 100
+	}
+	return stats, err
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+func TestReadContainerStatistics(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    string
+		want    models.ContainerStatistics
+		wantErr bool
+	}{
+		{
+			name: "v2 limited",
+			root: "v2",
+			want: models.ContainerStatistics{
+				CgroupVersion: 2,
+				Memory:        models.ContainerMemory{LimitBytes: 512 << 20, UsageBytes: 384 << 20, WorkingSetBytes: 256 << 20, OOMEvents: 3, OOMKills: 1},
+				CPU:           models.ContainerCPU{LimitCores: 1.5, UsageSeconds: 2.5, Periods: 200, ThrottledPeriods: 50, ThrottledSeconds: 1.5},
+			},
+		},
+		{
+			name: "v2 max is unlimited",
+			root: "v2-unlimited",
+			want: models.ContainerStatistics{
+				CgroupVersion: 2,
+				Memory:        models.ContainerMemory{UsageBytes: 100 << 20, WorkingSetBytes: 96 << 20},
+			},
+		},
+		{
+			name: "v2 missing files",
+			root: "v2-missing",
+			want: models.ContainerStatistics{CgroupVersion: 2},
+		},
+		{
+			name:    "v2 invalid limit",
+			root:    "v2-invalid",
+			want:    models.ContainerStatistics{CgroupVersion: 2},
+			wantErr: true,
+		},
+		{
+			name: "v1 limited",
+			root: "v1",
+			want: models.ContainerStatistics{
+				CgroupVersion: 1,
+				Memory:        models.ContainerMemory{LimitBytes: 256 << 20, UsageBytes: 200 << 20, WorkingSetBytes: 150 << 20, OOMEvents: 7, OOMKills: 2},
+				CPU:           models.ContainerCPU{LimitCores: 0.5, UsageSeconds: 3, Periods: 100, ThrottledPeriods: 10, ThrottledSeconds: 2},
+			},
+		},
+		{
+			name: "v1 huge limit is unlimited",
+			root: "v1-unlimited",
+			want: models.ContainerStatistics{
+				CgroupVersion: 1,
+				Memory:        models.ContainerMemory{UsageBytes: 70 << 20, WorkingSetBytes: 60 << 20},
+			},
+		},
+		{
+			name: "v1 missing files",
+			root: "v1-missing",
+			want: models.ContainerStatistics{CgroupVersion: 1},
+		},
+		{
+			name: "no cgroup",
+			root: "none",
+			want: models.ContainerStatistics{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadContainerStatistics(filepath.Join("testdata", "cgroup", tt.root))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadContainerStatistics() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadContainerStatistics() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetContainerStatisticsUsesWorkingSet(t *testing.T) {
+	SetCgroupRoot(filepath.Join("testdata", "cgroup", "v2"))
+	defer SetCgroupRoot("")
+
+	collectMu.Lock()
+	stats, err := GetContainerStatistics()
+	collectMu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Memory.UsagePercent != 50 {
+		t.Errorf("UsagePercent = %v, want 50, the working set of the limit", stats.Memory.UsagePercent)
+	}
+}
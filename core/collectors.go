@@ -0,0 +1,50 @@
+package core
+
+import (
+	"log"
+	"sort"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const (
+	CPUCollector       = "cpu"
+	LoadCollector      = "load"
+	MemoryCollector    = "memory"
+	NetworkCollector   = "network"
+	ContainerCollector = "container"
+	DiskCollector      = "disk"
+)
+
+var collectorErrors = make(map[string]int64) // Failed collections per collector, guarded by collectMu
+
+// collectorResults holds the errors of the collectors during a collection, the first error of a collector is kept.
+type collectorResults map[string]error
+
+// add records the result of a collector.
+func (r collectorResults) add(name string, err error) {
+	if _, ok := r[name]; !ok || r[name] == nil {
+		r[name] = err
+	}
+}
+
+// recordCollectorResults updates the error counts with the results of a collection and returns the status of every collector.
+// It must be called with collectMu held.
+func recordCollectorResults(results collectorResults) []models.CollectorStatus {
+	statuses := make([]models.CollectorStatus, 0, len(results))
+	for name, err := range results {
+		status := models.CollectorStatus{Name: name, Available: err == nil}
+		if err != nil {
+			if collectorErrors[name] == 0 {
+				log.Printf("[MoniGo] Collector %s is unavailable: %v\n", name, err) // Logging the first failure only, the next ones are counted
+			}
+			collectorErrors[name]++
+			status.LastError = err.Error()
+		}
+		status.ErrorCount = collectorErrors[name]
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
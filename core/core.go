@@ -2,7 +2,6 @@ package core
 
 import (
 	"fmt"
-	"log"
 	"runtime"
 	"strconv"
 	"sync"
@@ -12,57 +11,86 @@ import (
 	"github.com/iyashjayesh/monigo/models"
 	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/mem"
-	"github.com/shirou/gopsutil/net"
 )
 
-// GetServiceStats collects statistics related to service and system performance.
-func GetServiceStats() models.ServiceStats {
+// CollectServiceStats collects statistics related to service and system performance.
+// The CPU usage is measured since the previous collection, so the collection does not block.
+func CollectServiceStats() models.ServiceStats {
+	collectMu.Lock()
+	defer collectMu.Unlock()
+
+	start := time.Now()
+	results := make(collectorResults)
+	results.add(CPUCollector, sampleCPU())
 
 	var stats models.ServiceStats
 	stats.CoreStatistics = GetCoreStatistics()
+	stats.RequestStatistics = GetRequestStatistics()
+	stats.CustomMetrics = GetCustomMetrics()
+	stats.Functions = GetFunctionStatistics()
+	stats.Runtime = GetRuntimeStatistics()
+
+	// The memory statistics are read from runtime/metrics, as runtime.ReadMemStats stops the world
+	memStats := ReadMemStats()
+	stats.HeapAllocByService = common.BytesToUnit(memStats.HeapAlloc)
+	stats.HeapAllocBySystem = common.BytesToUnit(memStats.HeapSys)
+	stats.TotalAllocByService = common.BytesToUnit(memStats.TotalAlloc)
+	stats.TotalMemoryByOS = common.BytesToUnit(memStats.Sys)
 
 	var wg sync.WaitGroup
 	wg.Add(5)
 
+	var loadErr, memoryErr, cpuErr, networkErr, diskErr error
+
 	// Goroutine to fetch load statistics
 	go func() {
 		defer wg.Done()
-		stats.LoadStatistics = GetLoadStatistics()
+		stats.LoadStatistics, loadErr = GetLoadStatistics()
 	}()
 
 	// Goroutine to fetch memory statistics
 	go func() {
 		defer wg.Done()
-		stats.MemoryStatistics = GetMemoryStatistics()
+		stats.MemoryStatistics, memoryErr = GetMemoryStatistics(memStats)
 	}()
 
 	// Goroutine to fetch CPU statistics
 	go func() {
 		defer wg.Done()
-		stats.CPUStatistics = GetCPUStatistics()
+		stats.CPUStatistics, cpuErr = GetCPUStatistics()
 	}()
 
-	// Goroutine to fetch memory allocation statistics
+	// Goroutine to fetch network I/O statistics
 	go func() {
 		defer wg.Done()
-		memStats := ReadMemStats()
-		stats.HeapAllocByService = common.BytesToUnit(memStats.HeapAlloc)
-		stats.HeapAllocBySystem = common.BytesToUnit(memStats.HeapSys)
-		stats.TotalAllocByService = common.BytesToUnit(memStats.TotalAlloc)
-		stats.TotalMemoryByOS = common.BytesToUnit(memStats.Sys)
+		stats.NetworkIO, networkErr = GetNetworkIO()
 	}()
 
-	// Goroutine to fetch network I/O statistics
+	// Goroutine to fetch disk I/O statistics
 	go func() {
 		defer wg.Done()
-		stats.NetworkIO.BytesReceived, stats.NetworkIO.BytesSent = GetNetworkIO()
+		stats.DiskIO, diskErr = GetDiskIOStatistics()
 	}()
 
 	wg.Wait()
 
+	// The disk load is derived from the disk I/O, not read on its own
+	stats.LoadStatistics.ServiceDiskLoad, stats.LoadStatistics.SystemDiskLoad, stats.LoadStatistics.TotalDiskLoad = GetDiskLoad(stats.DiskIO)
+
+	results.add(LoadCollector, loadErr)
+	results.add(MemoryCollector, memoryErr)
+	results.add(CPUCollector, cpuErr)
+	results.add(NetworkCollector, networkErr)
+	results.add(DiskCollector, diskErr)
+
+	var containerErr error
+	stats.Container, containerErr = GetContainerStatistics()
+	results.add(ContainerCollector, containerErr)
+	stats.Collectors = recordCollectorResults(results)
+
 	stats.Health = GetServiceHealth(&stats)
-	// stats.DiskIO = GetDiskIO()  // TODO: Implement Disk I/O collection logic
 
+	stats.SamplerStatistics = recordCollection(start, time.Since(start))
 	return stats
 }
 
@@ -92,21 +120,27 @@ func GetCoreStatistics() models.CoreStatistics {
 	serviceInfo := common.GetServiceInfo()
 	uptime := time.Since(serviceInfo.ServiceStartTime)
 	uptimeFormatted := formatUptime(uptime)
+	requestCount, requestDuration := GetRequestCount()
 
 	return models.CoreStatistics{
-		Goroutines: runtime.NumGoroutine(),
-		Uptime:     uptimeFormatted,
+		Goroutines:                   runtime.NumGoroutine(),
+		Uptime:                       uptimeFormatted,
+		RequestCount:                 requestCount,
+		TotalDurationTookByRequestMs: common.RoundFloat64(durationToMs(requestDuration), 3),
 	}
 }
 
-// GetLoadStatistics retrieves load statistics for CPU, memory, and optionally disk usage.
-func GetLoadStatistics() models.LoadStatistics {
+// GetLoadStatistics retrieves load statistics for CPU and memory, the disk load is filled from the disk I/O statistics.
+func GetLoadStatistics() (models.LoadStatistics, error) {
 
 	// Fetch CPU load statistics
-	serviceCPULoad, systemCPULoad, totalCPULoad := common.GetCPULoad()
+	serviceCPULoad, systemCPULoad, totalCPULoad := common.GetCPULoad(lastCPUUsage.servicePercent, lastCPUUsage.systemPercent)
 
 	// Fetch memory load statistics
-	serviceMemLoad, systemMemLoad, totalMemAvailable := common.GetMemoryLoad()
+	serviceMemLoad, systemMemLoad, totalMemAvailable, err := common.GetMemoryLoad()
+	if err != nil {
+		return models.LoadStatistics{}, err
+	}
 
 	return models.LoadStatistics{
 		ServiceCPULoad:       serviceCPULoad,
@@ -116,11 +150,7 @@ func GetLoadStatistics() models.LoadStatistics {
 		SystemMemLoad:        systemMemLoad,
 		TotalMemLoad:         common.ConvertToReadableUnit(totalMemAvailable),
 		OverallLoadOfService: CalculateOverallLoad(serviceCPULoad, serviceMemLoad),
-		// Disk load can be added later if required
-		// ServiceDiskLoad: common.ParseFloat64ToString(serviceDisk), @TODO: Need to work on this
-		// SystemDiskLoad:  common.ParseFloat64ToString(systemDisk),  @TODO: Need to work on this
-		// TotalDiskLoad:   common.ParseFloat64ToString(totalDisk),
-	}
+	}, nil
 }
 
 // Function to calculate overall load
@@ -143,19 +173,20 @@ func CalculateOverallLoad(serviceCPU, serviceMem string) string {
 }
 
 // GetCPUStatistics retrieves the CPU statistics.
-func GetCPUStatistics() models.CPUStatistics {
+func GetCPUStatistics() (models.CPUStatistics, error) {
 	var cpuStats models.CPUStatistics
 
 	sysCPUPercent := GetCPUPrecent()
-	memInfo := GetVirtualMemoryStats()
+	procCPUPercent := lastCPUUsage.servicePercent
 
-	procCPUPercent, _, err := getProcessUsage(common.GetProcessObject(), &memInfo)
+	totalLogicalCores, err := cpu.Counts(true)
 	if err != nil {
-		log.Panicf("[MoniGo] Error fetching process usage: %v\n", err)
+		return cpuStats, fmt.Errorf("error fetching the number of logical cores: %w", err)
+	}
+	totalCores, err := cpu.Counts(false)
+	if err != nil {
+		return cpuStats, fmt.Errorf("error fetching the number of cores: %w", err)
 	}
-
-	totalLogicalCores, _ := cpu.Counts(true)
-	totalCores, _ := cpu.Counts(false)
 	systemUsedCores := (sysCPUPercent / 100) * float64(totalLogicalCores)
 	processUsedCores := (procCPUPercent / 100) * float64(totalLogicalCores)
 
@@ -168,23 +199,22 @@ func GetCPUStatistics() models.CPUStatistics {
 	cpuStats.CoresUsedBySystemInPercent = strconv.FormatFloat(cpuStats.CoresUsedBySystem, 'f', 2, 64) + "%"
 	cpuStats.CoresUsedByServiceInPercent = strconv.FormatFloat(cpuStats.CoresUsedByService, 'f', 2, 64) + "%"
 
-	return cpuStats
+	return cpuStats, nil
 }
 
-// GetMemoryStatistics retrieves memory statistics.
-func GetMemoryStatistics() models.MemoryStatistics {
+// GetMemoryStatistics retrieves memory statistics, the service statistics being read from the memory statistics of the collection.
+func GetMemoryStatistics(m *runtime.MemStats) (models.MemoryStatistics, error) {
 
 	memInfo, err := mem.VirtualMemory() // Fetcing system memory statistics
 	if err != nil {
-		log.Panicf("[MoniGo] Error fetching virtual memory info: %v", err)
+		return models.MemoryStatistics{}, fmt.Errorf("error fetching virtual memory info: %w", err)
 	}
 
 	swapInfo, err := mem.SwapMemory() // Fetching swap memory statistics
 	if err != nil {
-		log.Panicf("[MoniGo] Error fetching swap memory info: %v", err)
+		return models.MemoryStatistics{}, fmt.Errorf("error fetching swap memory info: %w", err)
 	}
 
-	m := ReadMemStats() // Get the memory statistics for the service
 	return models.MemoryStatistics{
 		TotalSystemMemory:   common.BytesToUnit(memInfo.Total),
 		MemoryUsedBySystem:  common.BytesToUnit(memInfo.Used),
@@ -196,7 +226,7 @@ func GetMemoryStatistics() models.MemoryStatistics {
 		GCPauseDuration:     fmt.Sprintf("%.2f ms", float64(m.PauseTotalNs)/float64(time.Millisecond)), // Convert nanoseconds to milliseconds
 		MemStatsRecords:     ConstructMemStats(m),
 		RawMemStatsRecords:  ConstructRawMemStats(m),
-	}
+	}, nil
 }
 
 // ConstructMemStats constructs a list of memory statistics records.
@@ -234,23 +264,6 @@ func ConstructMemStats(memStats *runtime.MemStats) []models.Record {
 	return r
 }
 
-// GetNetworkIO retrieves network I/O statistics.
-func GetNetworkIO() (float64, float64) {
-	// Fetch network I/O statistics
-	netIO, err := net.IOCounters(true)
-	if err != nil {
-		log.Panicf("[MoniGo] Error fetching network I/O statistics: %v", err)
-	}
-
-	var totalBytesReceived, totalBytesSent float64
-	for _, iface := range netIO { // Aggregate statistics from all network interfaces
-		totalBytesReceived += float64(iface.BytesRecv)
-		totalBytesSent += float64(iface.BytesSent)
-	}
-
-	return totalBytesReceived, totalBytesSent
-}
-
 // getStatusMessage returns a status message based on the health score.
 func getStatusMessage(healthScore float64) string {
 
@@ -311,7 +324,6 @@ func ConstructRawMemStats(memStats *runtime.MemStats) []models.RawMemStatsRecord
 		newRawRecord("alloc", float64(memStats.Alloc)),
 		newRawRecord("total_alloc", float64(memStats.TotalAlloc)),
 		newRawRecord("sys", float64(memStats.Sys)),
-		newRawRecord("sys", float64(memStats.Sys)),
 		newRawRecord("lookups", float64(memStats.Lookups)),
 		newRawRecord("mallocs", float64(memStats.Mallocs)),
 		newRawRecord("frees", float64(memStats.Frees)),
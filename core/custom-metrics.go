@@ -0,0 +1,328 @@
+package core
+
+import (
+	"log"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const (
+	CounterMetric   = "counter"
+	GaugeMetric     = "gauge"
+	HistogramMetric = "histogram"
+
+	CustomMetricPrefix  = "custom_"   // Prefix of the custom metric names, so they never collide with the series of monigo
+	reservedLabelPrefix = "exported_" // Prefix of the custom metric labels named like a label of monigo, as Prometheus does
+)
+
+// reservedLabels are the labels of the series of monigo and of the histograms, a custom metric label with the same name is prefixed.
+var reservedLabels = map[string]bool{"host": true, "service": true, "instance_id": true, "version": true, "le": true, "quantile": true}
+
+var (
+	customMetricsMu  sync.Mutex
+	customMetrics    = make(map[string]customMetric)
+	invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	DefaultBuckets   = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+)
+
+// customMetric is implemented by the metrics recorded through the custom metrics API.
+type customMetric interface {
+	metricType() string
+	snapshot() models.CustomMetric
+}
+
+// metricInfo holds the identity of a custom metric.
+type metricInfo struct {
+	name        string
+	description string
+	labels      map[string]string
+}
+
+// Counter is a custom metric that only goes up, ex. orders processed.
+type Counter struct {
+	metricInfo
+	value float64
+	mu    sync.Mutex
+}
+
+// Gauge is a custom metric that can go up and down, ex. queue depth.
+type Gauge struct {
+	metricInfo
+	value float64
+	mu    sync.Mutex
+}
+
+// Histogram is a custom metric counting observations in buckets, ex. order value.
+type Histogram struct {
+	metricInfo
+	upperBounds []float64
+	counts      []uint64 // Observations per bucket, the last one is the +Inf bucket
+	count       uint64
+	sum         float64
+	mu          sync.Mutex
+}
+
+// NewCounter registers and returns a counter, the existing counter is returned if it is already registered.
+func NewCounter(name, description string, labels map[string]string) *Counter {
+	c := &Counter{metricInfo: newMetricInfo(name, description, labels)}
+	return registerCustomMetric(c.metricInfo, c).(*Counter)
+}
+
+// NewGauge registers and returns a gauge, the existing gauge is returned if it is already registered.
+func NewGauge(name, description string, labels map[string]string) *Gauge {
+	g := &Gauge{metricInfo: newMetricInfo(name, description, labels)}
+	return registerCustomMetric(g.metricInfo, g).(*Gauge)
+}
+
+// NewHistogram registers and returns a histogram with the bucket upper bounds, the existing histogram is returned
+// if it is already registered. DefaultBuckets are used when no buckets are provided.
+func NewHistogram(name, description string, buckets []float64, labels map[string]string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	upperBounds := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		if !math.IsInf(b, 1) && !math.IsNaN(b) {
+			upperBounds = append(upperBounds, b)
+		}
+	}
+	sort.Float64s(upperBounds)
+
+	h := &Histogram{
+		metricInfo:  newMetricInfo(name, description, labels),
+		upperBounds: upperBounds,
+		counts:      make([]uint64, len(upperBounds)+1),
+	}
+	return registerCustomMetric(h.metricInfo, h).(*Histogram)
+}
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add increases the counter by the value, negative values are ignored as a counter can only go up.
+func (c *Counter) Add(value float64) {
+	if value < 0 {
+		log.Printf("[MoniGo] Ignoring negative value %v added to the counter %s\n", value, c.name)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value += value
+}
+
+// Set sets the gauge to the value.
+func (g *Gauge) Set(value float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value = value
+}
+
+// Inc increments the gauge by 1.
+func (g *Gauge) Inc() {
+	g.Add(1)
+}
+
+// Dec decrements the gauge by 1.
+func (g *Gauge) Dec() {
+	g.Add(-1)
+}
+
+// Add adds the value to the gauge.
+func (g *Gauge) Add(value float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value += value
+}
+
+// Sub subtracts the value from the gauge.
+func (g *Gauge) Sub(value float64) {
+	g.Add(-value)
+}
+
+// Observe adds an observation to the histogram.
+func (h *Histogram) Observe(value float64) {
+	idx := sort.SearchFloat64s(h.upperBounds, value) // First bucket with an upper bound >= value
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[idx]++
+	h.count++
+	h.sum += value
+}
+
+func (c *Counter) metricType() string   { return CounterMetric }
+func (g *Gauge) metricType() string     { return GaugeMetric }
+func (h *Histogram) metricType() string { return HistogramMetric }
+
+// snapshot returns the current state of the counter.
+func (c *Counter) snapshot() models.CustomMetric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metricInfo.model(CounterMetric, c.value)
+}
+
+// snapshot returns the current state of the gauge.
+func (g *Gauge) snapshot() models.CustomMetric {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.metricInfo.model(GaugeMetric, g.value)
+}
+
+// snapshot returns the current state of the histogram along with the percentiles estimated from the buckets.
+func (h *Histogram) snapshot() models.CustomMetric {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	m := h.metricInfo.model(HistogramMetric, 0)
+	m.Count = h.count
+	m.Sum = h.sum
+
+	var cumulative uint64
+	for i, upperBound := range h.upperBounds {
+		cumulative += h.counts[i]
+		m.Buckets = append(m.Buckets, models.HistogramBucket{UpperBound: upperBound, Count: cumulative})
+	}
+
+	m.P50 = bucketQuantile(0.5, m.Buckets, h.count)
+	m.P90 = bucketQuantile(0.9, m.Buckets, h.count)
+	m.P99 = bucketQuantile(0.99, m.Buckets, h.count)
+	return m
+}
+
+// bucketQuantile estimates the quantile by linear interpolation within the cumulative buckets.
+// Observations above the highest bucket are estimated as the highest upper bound.
+func bucketQuantile(q float64, buckets []models.HistogramBucket, count uint64) float64 {
+	if count == 0 || len(buckets) == 0 {
+		return 0
+	}
+
+	rank := q * float64(count)
+	var lowerBound float64
+	var lowerCount uint64
+	for _, b := range buckets {
+		if float64(b.Count) >= rank {
+			if b.Count == lowerCount {
+				return b.UpperBound
+			}
+			return lowerBound + (b.UpperBound-lowerBound)*(rank-float64(lowerCount))/float64(b.Count-lowerCount)
+		}
+		lowerBound, lowerCount = b.UpperBound, b.Count
+	}
+	return buckets[len(buckets)-1].UpperBound
+}
+
+// GetCustomMetrics returns the current state of every custom metric, sorted by name.
+func GetCustomMetrics() []models.CustomMetric {
+	customMetricsMu.Lock()
+	metrics := make([]customMetric, 0, len(customMetrics))
+	for _, m := range customMetrics {
+		metrics = append(metrics, m)
+	}
+	customMetricsMu.Unlock()
+
+	snapshots := make([]models.CustomMetric, 0, len(metrics))
+	for _, m := range metrics {
+		snapshots = append(snapshots, m.snapshot())
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].Name == snapshots[j].Name {
+			return labelsKey(snapshots[i].Labels) < labelsKey(snapshots[j].Labels)
+		}
+		return snapshots[i].Name < snapshots[j].Name
+	})
+	return snapshots
+}
+
+// registerCustomMetric registers the metric, returning the already registered one with the same name and labels if any.
+// A metric registered with the same name and labels but another type is returned unregistered.
+func registerCustomMetric(info metricInfo, metric customMetric) customMetric {
+	key := info.name + "{" + labelsKey(info.labels) + "}"
+
+	customMetricsMu.Lock()
+	defer customMetricsMu.Unlock()
+
+	if existing, ok := customMetrics[key]; ok {
+		if existing.metricType() == metric.metricType() {
+			if h, ok := metric.(*Histogram); ok && !slices.Equal(h.upperBounds, existing.(*Histogram).upperBounds) {
+				log.Printf("[MoniGo] Histogram %s is already registered with the buckets %v, the buckets %v are ignored\n", key, existing.(*Histogram).upperBounds, h.upperBounds)
+			}
+			return existing
+		}
+		log.Printf("[MoniGo] Metric %s is already registered as a %s, the %s will not be stored\n", key, existing.metricType(), metric.metricType())
+		return metric
+	}
+
+	customMetrics[key] = metric
+	return metric
+}
+
+// newMetricInfo builds the identity of a custom metric, replacing the characters not allowed in the names with underscores.
+// The name is prefixed with CustomMetricPrefix, and the labels named like a label of monigo are prefixed with "exported_".
+func newMetricInfo(name, description string, labels map[string]string) metricInfo {
+	info := metricInfo{
+		name:        CustomMetricPrefix + sanitizeMetricName(name),
+		description: description,
+		labels:      make(map[string]string, len(labels)),
+	}
+	for k, v := range labels {
+		if k == "" || v == "" {
+			continue // Labels with missing name or value are dropped by the storage
+		}
+		k = sanitizeMetricName(k)
+		if reservedLabels[k] {
+			log.Printf("[MoniGo] Label %q of metric %s is reserved, using %q instead\n", k, info.name, reservedLabelPrefix+k)
+			k = reservedLabelPrefix + k
+		}
+		info.labels[k] = v
+	}
+	return info
+}
+
+// model converts the metric info to the API model.
+func (i metricInfo) model(metricType string, value float64) models.CustomMetric {
+	labels := make(map[string]string, len(i.labels))
+	for k, v := range i.labels {
+		labels[k] = v
+	}
+
+	return models.CustomMetric{
+		Name:        i.name,
+		Description: i.description,
+		Type:        metricType,
+		Labels:      labels,
+		Value:       value,
+	}
+}
+
+// sanitizeMetricName replaces the characters not allowed in metric and label names with underscores.
+func sanitizeMetricName(name string) string {
+	sanitized := invalidNameChars.ReplaceAllString(name, "_")
+	if sanitized == "" || (sanitized[0] >= '0' && sanitized[0] <= '9') {
+		sanitized = "_" + sanitized
+	}
+	if sanitized != name {
+		log.Printf("[MoniGo] Invalid metric name %q, using %q instead\n", name, sanitized)
+	}
+	return sanitized
+}
+
+// labelsKey returns the labels as a string, independent of their order.
+func labelsKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/models"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/process"
+)
+
+var lastDiskIO diskIOCounters // Counters read by the previous collection, guarded by collectMu
+
+// diskIOCounters holds the disk I/O counters of the service and the devices at a point in time.
+type diskIOCounters struct {
+	at      time.Time
+	process *process.IOCountersStat
+	devices map[string]disk.IOCountersStat
+}
+
+// GetDiskIOStatistics reads the disk I/O of the service and the devices, measuring the rates since the previous collection,
+// and the usage of the filesystem holding the monigo data. The statistics that could be read are returned along with the errors.
+// It must be called with collectMu held.
+func GetDiskIOStatistics() (models.DiskIOStatistics, error) {
+	var stats models.DiskIOStatistics
+	var errs []error
+
+	current := diskIOCounters{at: time.Now()}
+	if proc, err := common.GetProcessObject(); err != nil {
+		errs = append(errs, err)
+	} else if current.process, err = proc.IOCounters(); err != nil {
+		errs = append(errs, fmt.Errorf("error fetching disk I/O of the service: %w", err))
+	}
+
+	devices, err := disk.IOCounters()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("error fetching disk I/O of the devices: %w", err))
+	}
+	current.devices = make(map[string]disk.IOCountersStat)
+	for name, device := range devices {
+		if isWholeDisk(name) {
+			current.devices[name] = device
+		}
+	}
+
+	previous := lastDiskIO
+	elapsed := current.at.Sub(previous.at).Seconds()
+	if previous.at.IsZero() {
+		elapsed = 0 // No rates on the first collection
+	}
+
+	if p := current.process; p != nil {
+		stats.Process = models.ProcessIO{ReadBytes: p.ReadBytes, WriteBytes: p.WriteBytes, ReadOps: p.ReadCount, WriteOps: p.WriteCount}
+		if prev := previous.process; prev != nil && elapsed > 0 {
+			stats.Process.ReadBytesPerSec = counterRate(prev.ReadBytes, p.ReadBytes, elapsed)
+			stats.Process.WriteBytesPerSec = counterRate(prev.WriteBytes, p.WriteBytes, elapsed)
+		}
+	}
+
+	for name, d := range current.devices {
+		device := models.DeviceIO{
+			Name:       name,
+			ReadBytes:  d.ReadBytes,
+			WriteBytes: d.WriteBytes,
+			ReadOps:    d.ReadCount,
+			WriteOps:   d.WriteCount,
+		}
+		if prev, ok := previous.devices[name]; ok && elapsed > 0 {
+			device.ReadBytesPerSec = counterRate(prev.ReadBytes, d.ReadBytes, elapsed)
+			device.WriteBytesPerSec = counterRate(prev.WriteBytes, d.WriteBytes, elapsed)
+			device.ReadOpsPerSec = counterRate(prev.ReadCount, d.ReadCount, elapsed)
+			device.WriteOpsPerSec = counterRate(prev.WriteCount, d.WriteCount, elapsed)
+			device.UtilizationPercent = min(counterRate(prev.IoTime, d.IoTime, elapsed)/10, 100) // Milliseconds busy per second
+		}
+		stats.Devices = append(stats.Devices, device)
+	}
+	sort.Slice(stats.Devices, func(i, j int) bool { return stats.Devices[i].Name < stats.Devices[j].Name })
+
+	basePath := common.GetBasePath()
+	if _, err := os.Stat(basePath); err != nil {
+		basePath = filepath.Dir(basePath) // The monigo folder is not created yet, ex. with a storage provided by the user
+	}
+	if usage, err := disk.Usage(basePath); err != nil {
+		errs = append(errs, fmt.Errorf("error fetching filesystem usage: %w", err))
+	} else {
+		stats.Filesystem = models.FilesystemUsage{
+			Path:              basePath,
+			Fstype:            usage.Fstype,
+			TotalBytes:        usage.Total,
+			UsedBytes:         usage.Used,
+			FreeBytes:         usage.Free,
+			UsedPercent:       common.RoundFloat64(usage.UsedPercent, 2),
+			InodesUsedPercent: common.RoundFloat64(usage.InodesUsedPercent, 2),
+		}
+	}
+
+	lastDiskIO = current
+	return stats, errors.Join(errs...)
+}
+
+// GetDiskLoad returns the share of the disk throughput done by the service, the utilization of the busiest device
+// and the used space of the filesystem holding the monigo data, as percentages.
+func GetDiskLoad(diskIO models.DiskIOStatistics) (serviceDisk, systemDisk, totalDisk string) {
+	var deviceBytesPerSec, busiest float64
+	for _, device := range diskIO.Devices {
+		deviceBytesPerSec += device.ReadBytesPerSec + device.WriteBytesPerSec
+		busiest = max(busiest, device.UtilizationPercent)
+	}
+
+	var serviceShare float64
+	if deviceBytesPerSec > 0 {
+		serviceShare = min((diskIO.Process.ReadBytesPerSec+diskIO.Process.WriteBytesPerSec)/deviceBytesPerSec*100, 100)
+	}
+
+	serviceDisk = common.ParseFloat64ToString(serviceShare) + "%"
+	systemDisk = common.ParseFloat64ToString(busiest) + "%"
+	totalDisk = common.ParseFloat64ToString(diskIO.Filesystem.UsedPercent) + "%"
+	return serviceDisk, systemDisk, totalDisk
+}
+
+// counterRate returns the per second rate of a counter, a counter reset yields no rate.
+func counterRate(previous, current uint64, elapsed float64) float64 {
+	if current < previous || elapsed <= 0 {
+		return 0
+	}
+	return common.RoundFloat64(float64(current-previous)/elapsed, 2)
+}
+
+// isWholeDisk returns whether the device is a disk rather than a partition or a virtual device, so the I/O is not counted twice.
+// Every device is kept when the block devices cannot be listed.
+func isWholeDisk(name string) bool {
+	if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+		return false
+	}
+
+	sysPath := common.DefaultIfEmpty(os.Getenv("HOST_SYS"), "/sys")
+	if _, err := os.Stat(filepath.Join(sysPath, "block")); err != nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(sysPath, "block", name))
+	return err == nil
+}
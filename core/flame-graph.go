@@ -0,0 +1,160 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const runtimeFrame = "runtime" // Name of the frame the consecutive runtime frames are collapsed into
+
+// FlameGraphOptions configures the flame graph of a profile.
+type FlameGraphOptions struct {
+	Focus           string // Only the samples with a function starting with the prefix are kept, rooted at the outermost such function
+	CollapseRuntime bool   // Consecutive runtime frames are collapsed into a single frame, and the runtime frames below main or goexit are dropped
+	SampleType      string // Sample type of the graph, ex. "alloc_space" for a heap profile. Defaults to the default sample type of the profile
+}
+
+// flameNode is a frame of a flame graph being built, the base values are only set for a differential flame graph.
+type flameNode struct {
+	name                string
+	self, total         int64
+	baseSelf, baseTotal int64
+	children            map[string]*flameNode
+}
+
+// GenerateFlameGraph decodes the profile and builds its flame graph, the frames of the samples merged from the root to the leaf.
+func GenerateFlameGraph(path string, options FlameGraphOptions) (models.FlameGraph, error) {
+	graph := models.FlameGraph{Path: path}
+
+	p, err := ReadProfile(path)
+	if err != nil {
+		return graph, err
+	}
+
+	index, err := sampleIndex(p, options.SampleType)
+	if err != nil {
+		return graph, err
+	}
+	graph.SampleType = p.SampleType[index].Type
+	graph.Unit = p.SampleType[index].Unit
+
+	root := newFlameNode("root")
+	for _, s := range p.Sample {
+		stack := flameStack(sampleFrames(s), options)
+		if stack == nil {
+			continue
+		}
+		root.add(stack, s.Value[index], false)
+	}
+
+	graph.Total = root.total
+	graph.Root = root.model()
+	return graph, nil
+}
+
+// flameStack returns the function names of the frames from the root to the leaf, nil when the sample is filtered out.
+func flameStack(frames []frame, options FlameGraphOptions) []string {
+	stack := make([]string, 0, len(frames))
+	for i := len(frames) - 1; i >= 0; i-- {
+		stack = append(stack, frames[i].function)
+	}
+
+	if options.Focus != "" {
+		focused := false
+		for i, function := range stack {
+			if strings.HasPrefix(function, options.Focus) {
+				stack, focused = stack[i:], true
+				break
+			}
+		}
+		if !focused {
+			return nil
+		}
+	}
+
+	if options.CollapseRuntime {
+		stack = collapseRuntimeFrames(stack)
+	}
+	return stack
+}
+
+// collapseRuntimeFrames drops the runtime frames at the root of the stack, ex. runtime.goexit or runtime.main,
+// and collapses the consecutive runtime frames into a single frame. A stack made of runtime frames only, ex. of the GC, is kept as one frame.
+func collapseRuntimeFrames(stack []string) []string {
+	start := 0
+	for start < len(stack) && isRuntimeFunction(stack[start]) {
+		start++
+	}
+	if start == len(stack) {
+		return []string{runtimeFrame}
+	}
+
+	collapsed := make([]string, 0, len(stack)-start)
+	for _, function := range stack[start:] {
+		if !isRuntimeFunction(function) {
+			collapsed = append(collapsed, function)
+		} else if collapsed[len(collapsed)-1] != runtimeFrame {
+			collapsed = append(collapsed, runtimeFrame)
+		}
+	}
+	return collapsed
+}
+
+// isRuntimeFunction returns whether the function belongs to the Go runtime.
+func isRuntimeFunction(function string) bool {
+	return strings.HasPrefix(function, "runtime.") || strings.HasPrefix(function, "runtime/internal/") || strings.HasPrefix(function, "internal/runtime/")
+}
+
+// newFlameNode returns an empty frame.
+func newFlameNode(name string) *flameNode {
+	return &flameNode{name: name, children: make(map[string]*flameNode)}
+}
+
+// add merges a stack ordered from the root to the leaf into the frame, into the base values when base is set.
+func (n *flameNode) add(stack []string, value int64, base bool) {
+	total, self := &n.total, &n.self
+	if base {
+		total, self = &n.baseTotal, &n.baseSelf
+	}
+	*total += value
+	if len(stack) == 0 {
+		*self += value
+		return
+	}
+
+	child, ok := n.children[stack[0]]
+	if !ok {
+		child = newFlameNode(stack[0])
+		n.children[stack[0]] = child
+	}
+	child.add(stack[1:], value, base)
+}
+
+// model returns the frame and its children, sorted by name as in a flame graph.
+func (n *flameNode) model() models.FlameGraphNode {
+	node := models.FlameGraphNode{Name: n.name, Self: n.self, Value: n.total}
+	for _, child := range n.children {
+		node.Children = append(node.Children, child.model())
+	}
+	sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].Name < node.Children[j].Name })
+	return node
+}
+
+// diffModel returns the frame and its children with the values of the target and the base.
+func (n *flameNode) diffModel() models.DiffFlameGraphNode {
+	node := models.DiffFlameGraphNode{
+		Name:      n.name,
+		Self:      n.self,
+		Value:     n.total,
+		BaseSelf:  n.baseSelf,
+		BaseValue: n.baseTotal,
+		Delta:     n.total - n.baseTotal,
+	}
+	for _, child := range n.children {
+		node.Children = append(node.Children, child.diffModel())
+	}
+	sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].Name < node.Children[j].Name })
+	return node
+}
@@ -1,80 +1,275 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/iyashjayesh/monigo/common"
 	"github.com/iyashjayesh/monigo/models"
 )
 
+const (
+	functionWindowSize   = 1024 // Number of recent calls kept per function for percentile calculation
+	functionProfilesKept = 5    // Number of recent profiled calls whose profile files are kept per function
+)
+
 var (
-	functionMetrics = make(map[string]*models.FunctionMetrics)
-	basePath        = common.GetBasePath()
+	functionMetrics = make(map[string]*functionMetric) // Guarded by mu
+
+	profileMu          sync.Mutex // Held while a call is CPU profiled
+	profileErrorLogged bool       // Guarded by profileMu
 )
 
-// TraceFunction traces the function and captures the metrics
-func TraceFunction(f func()) {
-	name := strings.ReplaceAll(runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name(), "/", "-") // Getting the name of the function
+// functionMetric holds the statistics of a traced function across its calls.
+type functionMetric struct {
+	last          models.FunctionMetrics // Details of the last call
+	callCount     int64
+	errorCount    int64
+	panicCount    int64
+	profiledCount int64
+	durations     summary
+	allocations   summary
+	profiles      []*functionProfile // Profiles of the most recent profiled calls, oldest first
+	parents       map[string]int64   // Calls made within traced parent functions, by parent name
+}
 
-	initialGoroutines := runtime.NumGoroutine() // Capturing the initial number of goroutines
-	var memStatsBefore, memStatsAfter runtime.MemStats
-	runtime.ReadMemStats(&memStatsBefore)
+// summary aggregates a value across calls, the percentiles are calculated over the most recent calls.
+type summary struct {
+	count           int64
+	min, max, total float64
+	window          *SampleWindow[float64]
+}
 
-	folderPath := fmt.Sprintf("%s/profiles", basePath)
-	if err := os.MkdirAll(folderPath, os.ModePerm); err != nil {
-		log.Panicf("[MoniGo] could not create profiles directory: %v", err)
+// observe adds a value to the summary.
+func (s *summary) observe(value float64) {
+	if s.window == nil {
+		s.window = NewSampleWindow[float64](functionWindowSize)
+		s.min, s.max = value, value
 	}
+	s.count++
+	s.min = min(s.min, value)
+	s.max = max(s.max, value)
+	s.total += value
+	s.window.Observe(value)
+}
 
-	cpuProfName := fmt.Sprintf("%s_cpu.prof", name)
-	cpuProfFilePath := filepath.Join(folderPath, cpuProfName)
-
-	memProfName := fmt.Sprintf("%s_mem.prof", name)
-	memProfFilePath := filepath.Join(folderPath, memProfName)
+// statistics returns the distribution of the observed values.
+func (s *summary) statistics() models.SummaryStatistics {
+	if s.window == nil {
+		return models.SummaryStatistics{}
+	}
 
-	cpuProfileFile, err := StartCPUProfile(cpuProfFilePath)
-	if err != nil {
-		log.Printf("[MoniGo] could not start CPU profile for function: " + name + " : Error: " + err.Error() + " will be retrying in the next iteration")
+	p := s.window.Percentiles(95, 99)
+	return models.SummaryStatistics{
+		Min:  common.RoundFloat64(s.min, 3),
+		Max:  common.RoundFloat64(s.max, 3),
+		Mean: common.RoundFloat64(s.total/float64(s.count), 3),
+		P95:  common.RoundFloat64(p[0], 3),
+		P99:  common.RoundFloat64(p[1], 3),
 	}
-	defer StopCPUProfile(cpuProfileFile)
+}
+
+// TraceFunction traces the function and captures the metrics, a panic of the function is recorded and propagated to the caller.
+// A single call is CPU profiled at a time as the Go runtime supports one CPU profile per process, the calls running
+// meanwhile are timed without a profile.
+func TraceFunction(f func()) {
+	name := strings.ReplaceAll(runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name(), "/", "-") // Getting the name of the function
+
+	initialGoroutines := runtime.NumGoroutine() // Capturing the initial number of goroutines
+	profile := startFunctionProfile(name)
 
+	allocatedBefore := heapAllocatedBytes() // Read after the profile is started, so its allocations are not counted
 	start := time.Now()
+	panicked := true // Cleared once the function returns, the panic is recorded without being recovered
+	defer func() {
+		if panicked {
+			profile.stop()
+			recordFunctionCall(name, functionCall{
+				details: models.FunctionMetrics{
+					FunctionLastRanAt: start,
+					ExecutionTime:     time.Since(start),
+				},
+				profile:  profile,
+				panicked: true,
+			})
+		}
+	}()
 	f()
 	elapsed := time.Since(start)
+	allocated := heapAllocatedBytes() - allocatedBefore
+	panicked = false
 
-	if err := WriteHeapProfile(memProfFilePath); err != nil {
-		log.Printf("[MoniGo] could not write memory profile for function: " + name + " : Error: " + err.Error() + " will be retrying in the next iteration")
-	}
+	profile.stop()
 
-	runtime.ReadMemStats(&memStatsAfter)
 	finalGoroutines := runtime.NumGoroutine() - initialGoroutines
 	if finalGoroutines < 0 {
 		finalGoroutines = 0
 	}
 
-	var memoryUsage uint64
-	if memStatsAfter.Alloc >= memStatsBefore.Alloc {
-		memoryUsage = memStatsAfter.Alloc - memStatsBefore.Alloc
+	recordFunctionCall(name, functionCall{
+		details: models.FunctionMetrics{
+			FunctionLastRanAt: start,
+			MemoryUsage:       allocated,
+			GoroutineCount:    finalGoroutines,
+			ExecutionTime:     elapsed,
+		},
+		allocated: &allocated,
+		profile:   profile,
+	})
+}
+
+// functionProfile holds the profile files of a profiled call.
+type functionProfile struct {
+	calledAt time.Time
+	cpuFile  *os.File
+	cpuPath  string
+	memPath  string // Empty when the heap profile could not be written
+}
+
+// startFunctionProfile starts the CPU profile of a call of the function, each call is written to its own files.
+// It returns nil when another call is being profiled or the profile could not be started.
+func startFunctionProfile(name string) *functionProfile {
+	if !profileMu.TryLock() {
+		return nil // Another call holds the CPU profiler
+	}
+
+	folderPath := filepath.Join(common.GetBasePath(), "profiles")
+	if err := os.MkdirAll(folderPath, os.ModePerm); err != nil {
+		logProfileError(fmt.Errorf("could not create profiles directory, calls are traced without profiling: %w", err))
+		profileMu.Unlock()
+		return nil
 	}
 
+	calledAt := time.Now()
+	id := strconv.FormatInt(calledAt.UnixNano(), 10)
+	profile := &functionProfile{
+		calledAt: calledAt,
+		cpuPath:  filepath.Join(folderPath, fmt.Sprintf("%s_%s_cpu.prof", name, id)),
+		memPath:  filepath.Join(folderPath, fmt.Sprintf("%s_%s_mem.prof", name, id)),
+	}
+
+	var err error
+	if profile.cpuFile, err = StartCPUProfile(profile.cpuPath); err != nil {
+		// The CPU profiler may be in use outside of monigo, ex. by net/http/pprof
+		logProfileError(fmt.Errorf("could not start CPU profile for function %s, calls are traced without profiling: %w", name, err))
+		profileMu.Unlock()
+		return nil
+	}
+	profileErrorLogged = false
+	return profile
+}
+
+// stop stops the CPU profile, writes the heap profile and releases the profiler for the next call.
+func (p *functionProfile) stop() {
+	if p == nil {
+		return
+	}
+	defer profileMu.Unlock()
+
+	StopCPUProfile(p.cpuFile)
+	if err := WriteHeapProfile(p.memPath); err != nil {
+		log.Printf("[MoniGo] could not write memory profile %s: %v\n", p.memPath, err)
+		os.Remove(p.memPath)
+		p.memPath = ""
+	}
+}
+
+// remove deletes the profile files.
+func (p *functionProfile) remove() {
+	os.Remove(p.cpuPath)
+	if p.memPath != "" {
+		os.Remove(p.memPath)
+	}
+}
+
+// logProfileError logs the error once until a profile is started again, so a busy profiler does not flood the logs.
+// It must be called with profileMu held.
+func logProfileError(err error) {
+	if !profileErrorLogged {
+		log.Printf("[MoniGo] %v\n", err)
+		profileErrorLogged = true
+	}
+}
+
+// functionCall holds the outcome of a call of a traced function.
+type functionCall struct {
+	details   models.FunctionMetrics // Details reported as the last call of the function
+	allocated *uint64                // Bytes allocated during the call, nil when not measured
+	profile   *functionProfile       // Nil when the call was not profiled
+	parent    string                 // Name of the traced function the call was made within
+	err       error
+	panicked  bool
+}
+
+// recordFunctionCall adds a call of the function to its statistics, and removes the profiles of the function exceeding functionProfilesKept.
+func recordFunctionCall(name string, call functionCall) {
+	for _, stale := range addFunctionCall(name, call) {
+		stale.remove()
+	}
+}
+
+// addFunctionCall adds a call of the function to its statistics, returning the profiles no longer kept.
+func addFunctionCall(name string, call functionCall) []*functionProfile {
 	mu.Lock()
 	defer mu.Unlock()
 
-	functionMetrics[name] = &models.FunctionMetrics{
-		FunctionLastRanAt:  start,
-		CPUProfileFilePath: cpuProfFilePath,
-		MemProfileFilePath: memProfFilePath,
-		MemoryUsage:        memoryUsage,
-		GoroutineCount:     finalGoroutines,
-		ExecutionTime:      elapsed,
+	metric, ok := functionMetrics[name]
+	if !ok {
+		metric = &functionMetric{}
+		functionMetrics[name] = metric
 	}
+
+	var stale []*functionProfile
+	if call.profile != nil {
+		call.details.CPUProfileFilePath = call.profile.cpuPath
+		call.details.MemProfileFilePath = call.profile.memPath
+		metric.profiledCount++
+		metric.profiles = append(metric.profiles, call.profile)
+		if excess := len(metric.profiles) - functionProfilesKept; excess > 0 {
+			stale = append(stale, metric.profiles[:excess]...)
+			metric.profiles = append([]*functionProfile{}, metric.profiles[excess:]...)
+		}
+	} else {
+		// The latest profile remains available for calls that could not be profiled
+		call.details.CPUProfileFilePath = metric.last.CPUProfileFilePath
+		call.details.MemProfileFilePath = metric.last.MemProfileFilePath
+	}
+
+	if call.parent != "" {
+		if metric.parents == nil {
+			metric.parents = make(map[string]int64)
+		}
+		metric.parents[call.parent]++
+	}
+	if call.err != nil {
+		call.details.Error = call.err.Error()
+	}
+
+	call.details.Name = name
+	metric.last = call.details
+	metric.callCount++
+	if call.err != nil || call.panicked {
+		metric.errorCount++
+	}
+	if call.panicked {
+		metric.panicCount++
+	}
+	metric.durations.observe(durationToMs(call.details.ExecutionTime))
+	if call.allocated != nil {
+		metric.allocations.observe(float64(*call.allocated))
+	}
+	return stale
 }
 
 // FunctionTraceDetails returns the function trace details
@@ -82,29 +277,86 @@ func FunctionTraceDetails() map[string]*models.FunctionMetrics {
 	mu.Lock()
 	defer mu.Unlock()
 
-	return functionMetrics
+	details := make(map[string]*models.FunctionMetrics, len(functionMetrics))
+	for name, metric := range functionMetrics {
+		details[name] = metric.model()
+	}
+	return details
+}
+
+// GetFunctionStatistics returns the statistics of every traced function, sorted by name.
+func GetFunctionStatistics() []models.FunctionMetrics {
+	mu.Lock()
+	defer mu.Unlock()
+
+	stats := make([]models.FunctionMetrics, 0, len(functionMetrics))
+	for _, metric := range functionMetrics {
+		stats = append(stats, *metric.model())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
 }
 
-// ViewFunctionMetrics generates the function metrics
-func ViewFunctionMetrics(name, reportType string, metrics *models.FunctionMetrics) models.FunctionTraceDetails {
-	// Function to execute the pprof command and return the output or log an error
-	executePprof := func(profileFilePath, reportType string) string {
-		cmd := exec.Command("go", "tool", "pprof", "-"+reportType, profileFilePath)
-		output, _ := cmd.Output()
-		return string(output)
+// model returns the statistics of the function, it must be called with mu held.
+func (m *functionMetric) model() *models.FunctionMetrics {
+	stats := m.last
+	stats.CallCount = m.callCount
+	stats.ErrorCount = m.errorCount
+	stats.PanicCount = m.panicCount
+	stats.ProfiledCallCount = m.profiledCount
+	stats.ExecutionTimeMs = m.durations.statistics()
+	stats.AllocatedBytes = m.allocations.statistics()
+	if len(m.parents) > 0 {
+		stats.Parents = maps.Clone(m.parents)
+	}
+	for i := len(m.profiles) - 1; i >= 0; i-- {
+		stats.Profiles = append(stats.Profiles, models.FunctionProfile{
+			CalledAt:           m.profiles[i].calledAt,
+			CPUProfileFilePath: m.profiles[i].cpuPath,
+			MemProfileFilePath: m.profiles[i].memPath,
+		})
 	}
+	return &stats
+}
 
-	// Generating the function code stack trace for CPU profile
-	codeStackView := exec.Command("go", "tool", "pprof", "-list", name, metrics.CPUProfileFilePath)
-	codeStack, _ := codeStackView.Output()
+// isFunctionProfile returns whether the path is the CPU or heap profile of a profiled call kept for a traced function.
+func isFunctionProfile(path string) bool {
+	mu.Lock()
+	defer mu.Unlock()
 
-	// Return the function trace details
-	return models.FunctionTraceDetails{
-		FunctionName: name,
-		CoreProfile: models.Profiles{
-			CPU: executePprof(metrics.CPUProfileFilePath, reportType),
-			Mem: executePprof(metrics.MemProfileFilePath, reportType),
-		},
-		FunctionCodeTrace: string(codeStack),
+	for _, metric := range functionMetrics {
+		for _, profile := range metric.profiles {
+			if path == profile.cpuPath || (path == profile.memPath && path != "") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ViewFunctionMetrics generates the reports of the CPU and heap profiles of the last profiled call of the function, the list
+// report is made of the function. The sample type of the options only applies to the heap profile. A profile that could not be
+// reported has the error set in its report, and an error is returned when neither could be.
+func ViewFunctionMetrics(name string, options ProfileReportOptions, metrics *models.FunctionMetrics) (models.FunctionTraceDetails, error) {
+	options.Function = name
+	details := models.FunctionTraceDetails{FunctionName: name, ReportType: options.Type}
+
+	cpuOptions := options
+	cpuOptions.SampleType = ""
+	cpuReport, cpuErr := GenerateProfileReport(metrics.CPUProfileFilePath, cpuOptions)
+	if cpuErr != nil {
+		cpuReport.Error = cpuErr.Error()
+	}
+	details.CPUProfile = cpuReport
+
+	memReport, memErr := GenerateProfileReport(metrics.MemProfileFilePath, options)
+	if memErr != nil {
+		memReport.Error = memErr.Error()
+	}
+	details.MemProfile = memReport
+
+	if cpuErr != nil && memErr != nil {
+		return details, errors.Join(cpuErr, memErr)
 	}
+	return details, nil
 }
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const (
+	DefaultLeakInterval         = time.Minute      // Time between two goroutine snapshots when none is given
+	DefaultLeakBlockedThreshold = 10 * time.Minute // Time a goroutine must have been blocked for to be counted as blocked when none is given
+	leakWindow                  = 30               // Snapshots a creation site is tracked over, its growth is only flagged over a full window
+	leakMinGrowth               = 5                // Goroutines a site must have gained over the window to be flagged
+	leakMinBlocked              = 5                // Goroutines of a site blocked beyond the threshold to be flagged, a lone idle listener is not a leak
+	leakHealthPenalty           = 10               // Percentage points of service health subtracted per suspect
+	maxLeakHealthPenalty        = 50
+)
+
+// LeakDetectionConfig configures the goroutine leak detection.
+type LeakDetectionConfig struct {
+	Interval         time.Duration // Time between two snapshots, the growth of a site is evaluated over the last 30
+	BlockedThreshold time.Duration // Time a goroutine must have been blocked for to be counted as blocked, the runtime reports it in minutes
+}
+
+var (
+	leakMu           sync.Mutex           // Guards the tracked sites and the leak detection goroutine
+	leakSites        map[string]*leakSite // Tracked creation sites, by go statement
+	leakSnapshots    int                  // Snapshots taken since the detection started
+	leakLastSnapshot time.Time
+	leakConfig       LeakDetectionConfig // Zero when the detection is not running
+	leakCancel       context.CancelFunc  // Cancel function for the leak detection goroutine
+	leakWg           sync.WaitGroup      // Waits for the leak detection goroutine to stop
+)
+
+// leakSite holds the goroutines of a creation site across the snapshots.
+type leakSite struct {
+	createdBy models.GoroutineFrame
+	history   []models.GoroutineSiteCount // Oldest first, at most leakWindow
+	last      siteSnapshot
+}
+
+// siteSnapshot holds the goroutines of a creation site in a snapshot.
+type siteSnapshot struct {
+	count   int
+	blocked int
+	maxWait int
+	states  map[string]int
+	frames  []models.GoroutineFrame
+}
+
+// StartLeakDetection snapshots the goroutines every interval in the background and tracks the number of goroutines
+// of each creation site, flagging the sites whose count keeps growing or whose goroutines stay blocked.
+func StartLeakDetection(config LeakDetectionConfig) {
+	StopLeakDetection() // Stopping the previous detection, if any
+
+	if config.Interval <= 0 {
+		config.Interval = DefaultLeakInterval
+	}
+	if config.BlockedThreshold <= 0 {
+		config.BlockedThreshold = DefaultLeakBlockedThreshold
+	}
+
+	leakMu.Lock()
+	leakSites = make(map[string]*leakSite)
+	leakSnapshots = 0
+	leakLastSnapshot = time.Time{}
+	leakConfig = config
+	var ctx context.Context
+	ctx, leakCancel = context.WithCancel(context.Background())
+	leakMu.Unlock()
+
+	leakWg.Add(1)
+	go func() {
+		defer leakWg.Done()
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			snapshotGoroutineSites(time.Now(), config.BlockedThreshold)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StopLeakDetection stops the leak detection and waits for it to return, the suspects no longer affect the service health.
+func StopLeakDetection() {
+	leakMu.Lock()
+	if leakCancel != nil {
+		leakCancel()
+		leakCancel = nil
+	}
+	leakMu.Unlock()
+
+	leakWg.Wait()
+
+	leakMu.Lock()
+	leakConfig = LeakDetectionConfig{}
+	leakMu.Unlock()
+}
+
+// snapshotGoroutineSites counts the goroutines of every creation site and adds the counts to the history of the sites.
+func snapshotGoroutineSites(now time.Time, blockedThreshold time.Duration) {
+	bySite := make(map[string][]models.Goroutine)
+	createdBy := make(map[string]models.GoroutineFrame)
+	for _, block := range SplitGoroutines(string(goroutineStacks())) { // Parsed directly, the raw blocks are not kept
+		goroutine, ok := ParseGoroutine(block)
+		if !ok || goroutine.CreatedBy == nil {
+			continue // The main goroutine has no creation site
+		}
+		key := goroutineSiteKey(*goroutine.CreatedBy)
+		bySite[key] = append(bySite[key], goroutine)
+		createdBy[key] = *goroutine.CreatedBy
+	}
+
+	current := make(map[string]siteSnapshot, len(bySite))
+	for key, siteGoroutines := range bySite {
+		snapshot := siteSnapshot{count: len(siteGoroutines), states: make(map[string]int)}
+		for _, goroutine := range siteGoroutines {
+			snapshot.states[goroutine.State]++
+			snapshot.maxWait = max(snapshot.maxWait, goroutine.WaitMinutes)
+			if time.Duration(goroutine.WaitMinutes)*time.Minute >= blockedThreshold {
+				snapshot.blocked++
+			}
+		}
+		snapshot.frames = GroupGoroutines(siteGoroutines)[0].Frames
+		current[key] = snapshot
+	}
+
+	leakMu.Lock()
+	defer leakMu.Unlock()
+
+	for key, snapshot := range current {
+		if _, ok := leakSites[key]; !ok {
+			leakSites[key] = &leakSite{createdBy: createdBy[key]}
+		}
+		leakSites[key].last = snapshot
+	}
+	for key, site := range leakSites {
+		if _, ok := current[key]; !ok {
+			site.last = siteSnapshot{} // The goroutines of the site have all returned
+		}
+		site.history = append(site.history, models.GoroutineSiteCount{Time: now, Count: site.last.count})
+		if excess := len(site.history) - leakWindow; excess > 0 {
+			site.history = append([]models.GoroutineSiteCount{}, site.history[excess:]...)
+		}
+		if site.idle() {
+			delete(leakSites, key)
+		}
+	}
+	leakSnapshots++
+	leakLastSnapshot = now
+}
+
+// goroutineSiteKey returns the key identifying the go statement of a creation site.
+func goroutineSiteKey(frame models.GoroutineFrame) string {
+	return frame.Function + " " + frame.File + ":" + strconv.Itoa(frame.Line)
+}
+
+// idle returns whether the site had no goroutine over its whole history.
+func (s *leakSite) idle() bool {
+	for _, point := range s.history {
+		if point.Count > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reasons returns why the site is suspected of leaking goroutines, none when it is not.
+// The growth is only flagged over a full window and when the site grew in both halves of it, so a pool
+// filling up at startup or after a burst of traffic, then holding steady, is not suspected.
+func (s *leakSite) reasons(blockedThreshold time.Duration) []string {
+	var reasons []string
+	if len(s.history) >= leakWindow {
+		monotonic := true
+		for i := 1; i < len(s.history); i++ {
+			if s.history[i].Count < s.history[i-1].Count {
+				monotonic = false
+				break
+			}
+		}
+		first, middle, last := s.history[0].Count, s.history[len(s.history)/2].Count, s.history[len(s.history)-1].Count
+		if monotonic && middle > first && last > middle && last-first >= leakMinGrowth {
+			reasons = append(reasons, fmt.Sprintf("grew from %d to %d goroutines over %d snapshots without decreasing", first, last, len(s.history)))
+		}
+	}
+	if s.last.blocked >= leakMinBlocked {
+		reasons = append(reasons, fmt.Sprintf("%d goroutines blocked for at least %s", s.last.blocked, blockedThreshold))
+	}
+	return reasons
+}
+
+// model returns the creation site, it must be called with leakMu held.
+func (s *leakSite) model(reasons []string) models.GoroutineCreationSite {
+	site := models.GoroutineCreationSite{
+		CreatedBy:      s.createdBy,
+		Count:          s.last.count,
+		BlockedCount:   s.last.blocked,
+		MaxWaitMinutes: s.last.maxWait,
+		States:         make(map[string]int, len(s.last.states)),
+		Frames:         s.last.frames,
+		History:        append([]models.GoroutineSiteCount{}, s.history...),
+		Reasons:        reasons,
+	}
+	for state, count := range s.last.states {
+		site.States[state] = count
+	}
+	if len(s.history) > 0 {
+		site.Growth = s.history[len(s.history)-1].Count - s.history[0].Count
+	}
+	return site
+}
+
+// GetGoroutineLeaks returns the creation sites suspected of leaking goroutines, along with every tracked site when all is set.
+func GetGoroutineLeaks(all bool) models.GoroutineLeakReport {
+	leakMu.Lock()
+	defer leakMu.Unlock()
+
+	report := models.GoroutineLeakReport{
+		Enabled:                 leakConfig.Interval > 0,
+		IntervalSeconds:         leakConfig.Interval.Seconds(),
+		BlockedThresholdSeconds: leakConfig.BlockedThreshold.Seconds(),
+		Snapshots:               leakSnapshots,
+		LastSnapshotAt:          leakLastSnapshot,
+		Suspects:                []models.GoroutineCreationSite{},
+	}
+	if !report.Enabled {
+		return report
+	}
+
+	for _, site := range leakSites {
+		reasons := site.reasons(leakConfig.BlockedThreshold)
+		if len(reasons) > 0 {
+			report.Suspects = append(report.Suspects, site.model(reasons))
+		}
+		if all {
+			report.Sites = append(report.Sites, site.model(reasons))
+		}
+	}
+	sortCreationSites(report.Suspects)
+	sortCreationSites(report.Sites)
+	report.HealthPenalty = leakPenalty(len(report.Suspects))
+	return report
+}
+
+// sortCreationSites sorts the sites by the number of goroutines, the largest first.
+func sortCreationSites(sites []models.GoroutineCreationSite) {
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Count != sites[j].Count {
+			return sites[i].Count > sites[j].Count
+		}
+		return goroutineSiteKey(sites[i].CreatedBy) < goroutineSiteKey(sites[j].CreatedBy)
+	})
+}
+
+// goroutineLeakPenalty returns the percentage points subtracted from the service health and the number of suspects.
+func goroutineLeakPenalty() (float64, int) {
+	leakMu.Lock()
+	defer leakMu.Unlock()
+
+	if leakConfig.Interval == 0 {
+		return 0, 0
+	}
+	suspects := 0
+	for _, site := range leakSites {
+		if len(site.reasons(leakConfig.BlockedThreshold)) > 0 {
+			suspects++
+		}
+	}
+	return leakPenalty(suspects), suspects
+}
+
+// leakPenalty returns the percentage points subtracted from the service health for the suspects.
+func leakPenalty(suspects int) float64 {
+	return min(float64(suspects)*leakHealthPenalty, maxLeakHealthPenalty)
+}
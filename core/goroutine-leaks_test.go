@@ -0,0 +1,104 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+// siteHistory returns a history of the counts, a snapshot a minute.
+func siteHistory(counts ...int) []models.GoroutineSiteCount {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]models.GoroutineSiteCount, len(counts))
+	for i, count := range counts {
+		history[i] = models.GoroutineSiteCount{Time: start.Add(time.Duration(i) * time.Minute), Count: count}
+	}
+	return history
+}
+
+// window returns leakWindow counts computed from the snapshot index.
+func window(count func(i int) int) []int {
+	counts := make([]int, leakWindow)
+	for i := range counts {
+		counts[i] = count(i)
+	}
+	return counts
+}
+
+func TestLeakSiteReasons(t *testing.T) {
+	tests := []struct {
+		name    string
+		counts  []int
+		blocked int
+		want    int // Number of reasons
+	}{
+		{name: "steady growth over the window", counts: window(func(i int) int { return 10 + i }), want: 1},
+		{name: "growth in steps over the window", counts: window(func(i int) int { return 10 + 2*(i/5) }), want: 1},
+		{name: "pool filled at startup", counts: window(func(i int) int { return min(5*i, 20) }), want: 0},
+		{name: "pool filled after a burst", counts: window(func(i int) int { return 10 + 10*min(max(i-20, 0), 1) }), want: 0},
+		{name: "growth shorter than the window", counts: window(func(i int) int { return i })[:leakWindow-1], want: 0},
+		{name: "growth with a decrease", counts: window(func(i int) int { return i - 3*min(max(i-25, 0), 1) }), want: 0},
+		{name: "growth below the minimum", counts: window(func(i int) int { return 10 + i/10 }), want: 0},
+		{name: "steady count", counts: window(func(int) int { return 8 }), want: 0},
+		{name: "blocked goroutines", counts: []int{8, 8}, blocked: leakMinBlocked, want: 1},
+		{name: "few blocked goroutines", counts: []int{8, 8}, blocked: leakMinBlocked - 1, want: 0},
+		{name: "growth and blocked goroutines", counts: window(func(i int) int { return i }), blocked: leakMinBlocked, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := &leakSite{history: siteHistory(tt.counts...), last: siteSnapshot{blocked: tt.blocked}}
+			if got := site.reasons(10 * time.Minute); len(got) != tt.want {
+				t.Errorf("reasons() = %q, want %d reasons", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeakPenalty(t *testing.T) {
+	for suspects, want := range map[int]float64{0: 0, 1: leakHealthPenalty, 3: 3 * leakHealthPenalty, 100: maxLeakHealthPenalty} {
+		if got := leakPenalty(suspects); got != want {
+			t.Errorf("leakPenalty(%d) = %v, want %v", suspects, got, want)
+		}
+	}
+}
+
+func TestSnapshotGoroutineSites(t *testing.T) {
+	leakMu.Lock()
+	leakSites = make(map[string]*leakSite)
+	leakMu.Unlock()
+	defer func() {
+		leakMu.Lock()
+		leakSites, leakSnapshots = nil, 0
+		leakMu.Unlock()
+	}()
+
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		go func() {
+			started <- struct{}{}
+			<-block
+		}()
+		<-started
+	}
+
+	snapshotGoroutineSites(time.Now(), time.Minute)
+
+	leakMu.Lock()
+	defer leakMu.Unlock()
+	for _, site := range leakSites {
+		if site.createdBy.Function == "github.com/iyashjayesh/monigo/core.TestSnapshotGoroutineSites" {
+			if site.last.count != 3 || len(site.history) != 1 || site.history[0].Count != 3 {
+				t.Errorf("site = %+v, want 3 goroutines in a single snapshot", site)
+			}
+			if site.last.states["chan receive"] != 3 {
+				t.Errorf("states = %v, want 3 goroutines in chan receive", site.last.states)
+			}
+			return
+		}
+	}
+	t.Errorf("the site of the test goroutines was not tracked among %d sites", len(leakSites))
+}
@@ -0,0 +1,233 @@
+package core
+
+import (
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const goroutineStackBufferSize = 1 << 20 // Initial size of the buffer the stacks of the goroutines are written to, doubled until they fit
+
+// GoroutineFilter selects the goroutines of a stack dump, the zero value selects every goroutine.
+type GoroutineFilter struct {
+	State    string        // State of the goroutines, ex. "chan receive", matched case-insensitively
+	Function string        // Part of the name of a function in the stack of the goroutines, ex. "net/http."
+	MinWait  time.Duration // Time the goroutines have been blocked for, the runtime reports it in minutes from 1 minute
+}
+
+// AnalyzeGoroutines parses the stacks of every goroutine and returns the goroutines matching the filter,
+// grouped by identical stacks when group is set. The state breakdown is of every goroutine.
+func AnalyzeGoroutines(filter GoroutineFilter, group bool) models.GoRoutinesStatistic {
+	blocks := SplitGoroutines(string(goroutineStacks()))
+	stats := models.GoRoutinesStatistic{
+		NumberOfGoroutines: runtime.NumGoroutine(),
+		States:             make(map[string]int),
+		StackView:          []string{},
+	}
+
+	var matched []models.Goroutine
+	for _, block := range blocks {
+		goroutine, ok := ParseGoroutine(block)
+		if !ok {
+			continue
+		}
+		stats.States[goroutine.State]++
+		if filter.matches(goroutine) {
+			matched = append(matched, goroutine)
+			stats.StackView = append(stats.StackView, block)
+		}
+	}
+
+	stats.MatchedGoroutines = len(matched)
+	if group {
+		stats.Groups = GroupGoroutines(matched)
+	} else {
+		stats.Goroutines = matched
+	}
+	return stats
+}
+
+// goroutineStacks returns the stacks of every goroutine, growing the buffer until the dump is complete.
+func goroutineStacks() []byte {
+	buf := make([]byte, goroutineStackBufferSize)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf)) // The dump was truncated
+	}
+}
+
+// matches returns whether the goroutine is selected by the filter.
+func (f GoroutineFilter) matches(goroutine models.Goroutine) bool {
+	if f.State != "" && !strings.EqualFold(goroutine.State, f.State) {
+		return false
+	}
+	if f.MinWait > 0 && time.Duration(goroutine.WaitMinutes)*time.Minute < f.MinWait {
+		return false
+	}
+	if f.Function == "" {
+		return true
+	}
+	for _, frame := range goroutine.Frames {
+		if strings.Contains(frame.Function, f.Function) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseGoroutine parses a goroutine block of a stack dump, as split by SplitGoroutines, ex.
+//
+//	goroutine 7 [chan receive, 5 minutes]:
+//	main.worker(0xc000010000)
+//		/app/main.go:14 +0x19
+//	created by main.main in goroutine 1
+//		/app/main.go:10 +0x9f
+//
+// It returns false when the block does not start with a goroutine header.
+func ParseGoroutine(block string) (models.Goroutine, bool) {
+	lines := strings.Split(strings.TrimRight(block, "\n"), "\n")
+	goroutine, ok := parseGoroutineHeader(lines[0])
+	if !ok {
+		return goroutine, false
+	}
+
+	goroutine.Frames = []models.GoroutineFrame{}
+	for i := 1; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "...") { // ...additional frames elided...
+			goroutine.FramesElided = true
+			continue
+		}
+
+		frame := models.GoroutineFrame{}
+		creator := strings.HasPrefix(line, "created by ")
+		if creator {
+			function, goroutineID, found := strings.Cut(strings.TrimPrefix(line, "created by "), " in goroutine ")
+			frame.Function = function
+			if found {
+				goroutine.CreatorGoroutineID, _ = strconv.ParseInt(goroutineID, 10, 64)
+			}
+		} else {
+			frame.Function = trimFrameArguments(line)
+		}
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") { // The location of the call follows the function
+			frame.File, frame.Line = parseFrameLocation(lines[i+1])
+			i++
+		}
+
+		if creator {
+			goroutine.CreatedBy = &frame
+		} else {
+			goroutine.Frames = append(goroutine.Frames, frame)
+		}
+	}
+	return goroutine, true
+}
+
+// parseGoroutineHeader parses the ID and the status of a goroutine, ex. "goroutine 7 [chan receive, 5 minutes, locked to thread]:".
+func parseGoroutineHeader(line string) (models.Goroutine, bool) {
+	var goroutine models.Goroutine
+	rest, ok := strings.CutPrefix(line, "goroutine ")
+	if !ok {
+		return goroutine, false
+	}
+	id, rest, _ := strings.Cut(rest, " ")
+	var err error
+	if goroutine.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return goroutine, false
+	}
+
+	start, end := strings.Index(rest, "["), strings.LastIndex(rest, "]") // Details such as gp=0x... may precede the status
+	if start < 0 || end < start {
+		return goroutine, false
+	}
+	for i, part := range strings.Split(rest[start+1:end], ", ") {
+		switch {
+		case i == 0:
+			goroutine.State = part
+		case part == "locked to thread":
+			goroutine.LockedToThread = true
+		case strings.HasSuffix(part, " minutes") || strings.HasSuffix(part, " minute"):
+			goroutine.WaitMinutes, _ = strconv.Atoi(strings.Fields(part)[0])
+		}
+	}
+	return goroutine, true
+}
+
+// trimFrameArguments returns the function of a frame without its arguments, ex. "main.(*T).Run" for "main.(*T).Run(0xc000010000, ...)".
+func trimFrameArguments(line string) string {
+	if strings.HasSuffix(line, ")") {
+		if i := strings.LastIndex(line, "("); i > 0 {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseFrameLocation parses the file and line of a frame, ex. "\t/app/main.go:14 +0x19".
+func parseFrameLocation(line string) (string, int) {
+	location, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+	i := strings.LastIndex(location, ":")
+	if i < 0 {
+		return location, 0
+	}
+	lineNumber, err := strconv.Atoi(location[i+1:])
+	if err != nil {
+		return location, 0
+	}
+	return location[:i], lineNumber
+}
+
+// GroupGoroutines groups the goroutines with an identical stack and creation site, the largest groups first.
+func GroupGoroutines(goroutines []models.Goroutine) []models.GoroutineGroup {
+	groups := make(map[string]*models.GoroutineGroup)
+	var keys []string
+	for _, goroutine := range goroutines {
+		key := goroutineStackKey(goroutine)
+		group, ok := groups[key]
+		if !ok {
+			group = &models.GoroutineGroup{
+				States:         make(map[string]int),
+				MinWaitMinutes: goroutine.WaitMinutes,
+				Frames:         goroutine.Frames,
+				CreatedBy:      goroutine.CreatedBy,
+			}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.Count++
+		group.States[goroutine.State]++
+		group.MinWaitMinutes = min(group.MinWaitMinutes, goroutine.WaitMinutes)
+		group.MaxWaitMinutes = max(group.MaxWaitMinutes, goroutine.WaitMinutes)
+		group.IDs = append(group.IDs, goroutine.ID)
+	}
+
+	result := make([]models.GoroutineGroup, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *groups[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Count > result[j].Count })
+	return result
+}
+
+// goroutineStackKey returns the key identifying the stack and the creation site of a goroutine.
+func goroutineStackKey(goroutine models.Goroutine) string {
+	var key strings.Builder
+	for _, frame := range goroutine.Frames {
+		key.WriteString(frame.Function + " " + frame.File + ":" + strconv.Itoa(frame.Line) + "\n")
+	}
+	if goroutine.CreatedBy != nil {
+		key.WriteString("created by " + goroutine.CreatedBy.Function + " " + goroutine.CreatedBy.File + ":" + strconv.Itoa(goroutine.CreatedBy.Line))
+	}
+	return key.String()
+}
@@ -0,0 +1,173 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+// readGoroutineDump parses the goroutines of a captured stack dump under testdata/goroutines.
+func readGoroutineDump(t *testing.T, name string) []models.Goroutine {
+	t.Helper()
+	dump, err := os.ReadFile(filepath.Join("testdata", "goroutines", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var goroutines []models.Goroutine
+	for _, block := range SplitGoroutines(string(dump)) {
+		goroutine, ok := ParseGoroutine(block)
+		if !ok {
+			t.Fatalf("ParseGoroutine(%q) failed", block)
+		}
+		goroutines = append(goroutines, goroutine)
+	}
+	return goroutines
+}
+
+func TestParseGoroutineHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   models.Goroutine
+		wantOk bool
+	}{
+		{header: "goroutine 1 [running]:", want: models.Goroutine{ID: 1, State: "running"}, wantOk: true},
+		{header: "goroutine 7 [chan receive, 5 minutes]:", want: models.Goroutine{ID: 7, State: "chan receive", WaitMinutes: 5}, wantOk: true},
+		{header: "goroutine 6 [select, 1 minute]:", want: models.Goroutine{ID: 6, State: "select", WaitMinutes: 1}, wantOk: true},
+		{header: "goroutine 9 [syscall, 3 minutes, locked to thread]:", want: models.Goroutine{ID: 9, State: "syscall", WaitMinutes: 3, LockedToThread: true}, wantOk: true},
+		{header: "goroutine 4 [GC worker (idle)]:", want: models.Goroutine{ID: 4, State: "GC worker (idle)"}, wantOk: true},
+		{header: "goroutine 18 gp=0xc000102380 m=nil [IO wait, 7 minutes]:", want: models.Goroutine{ID: 18, State: "IO wait", WaitMinutes: 7}, wantOk: true},
+		{header: "goroutine 1 gp=0xc000002380 m=0 mp=0x5a8e40 [running]:", want: models.Goroutine{ID: 1, State: "running"}, wantOk: true},
+		{header: "goroutine x [running]:"},
+		{header: "goroutine 3 running"},
+		{header: "main.main()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, ok := parseGoroutineHeader(tt.header)
+			if ok != tt.wantOk {
+				t.Fatalf("parseGoroutineHeader() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseGoroutineHeader() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGoroutineDumps(t *testing.T) {
+	mainGoroutine := models.Goroutine{ID: 1, State: "running", Frames: []models.GoroutineFrame{{Function: "main.main", File: "/app/main.go", Line: 25}}}
+	workerFrames := []models.GoroutineFrame{{Function: "main.worker", File: "/app/main.go", Line: 14}}
+	createdByMain := &models.GoroutineFrame{Function: "main.main", File: "/app/main.go", Line: 10}
+
+	tests := []struct {
+		dump string
+		want []models.Goroutine
+	}{
+		{
+			dump: "go1.22.txt", // created by ... in goroutine N, since Go 1.21
+			want: []models.Goroutine{
+				mainGoroutine,
+				{ID: 7, State: "chan receive", WaitMinutes: 5, Frames: workerFrames, CreatedBy: createdByMain, CreatorGoroutineID: 1},
+				{ID: 8, State: "chan receive", WaitMinutes: 12, Frames: workerFrames, CreatedBy: createdByMain, CreatorGoroutineID: 1},
+				{
+					ID: 9, State: "syscall", WaitMinutes: 3, LockedToThread: true,
+					Frames: []models.GoroutineFrame{
+						{Function: "syscall.Syscall6", File: "/usr/local/go/src/syscall/syscall_linux.go", Line: 91},
+						{Function: "main.(*poller).poll", File: "/app/poll.go", Line: 8},
+					},
+					CreatedBy:          &models.GoroutineFrame{Function: "main.init.0", File: "/app/poll.go", Line: 4},
+					CreatorGoroutineID: 1,
+				},
+				{
+					ID: 10, State: "select",
+					Frames: []models.GoroutineFrame{
+						{Function: "main.recurse", File: "/app/recurse.go", Line: 6},
+						{Function: "main.recurse", File: "/app/recurse.go", Line: 7},
+					},
+					FramesElided:       true,
+					CreatedBy:          &models.GoroutineFrame{Function: "main.main", File: "/app/main.go", Line: 12},
+					CreatorGoroutineID: 1,
+				},
+			},
+		},
+		{
+			dump: "go1.20.txt", // created by ... without the creator goroutine
+			want: []models.Goroutine{
+				mainGoroutine,
+				{ID: 6, State: "chan receive", WaitMinutes: 1, Frames: workerFrames, CreatedBy: createdByMain},
+			},
+		},
+		{
+			dump: "go1.23-traceback-system.txt", // gp= and m= details before the status, with GOTRACEBACK=system
+			want: []models.Goroutine{
+				mainGoroutine,
+				{
+					ID: 18, State: "IO wait", WaitMinutes: 7,
+					Frames: []models.GoroutineFrame{
+						{Function: "internal/poll.runtime_pollWait", File: "/usr/local/go/src/runtime/netpoll.go", Line: 351},
+						{Function: "net/http.(*conn).serve", File: "/usr/local/go/src/net/http/server.go", Line: 2092},
+					},
+					CreatedBy:          &models.GoroutineFrame{Function: "net/http.(*Server).Serve", File: "/usr/local/go/src/net/http/server.go", Line: 3360},
+					CreatorGoroutineID: 1,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dump, func(t *testing.T) {
+			got := readGoroutineDump(t, tt.dump)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parsed %d goroutines, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("goroutine %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGroupGoroutines(t *testing.T) {
+	groups := GroupGoroutines(readGoroutineDump(t, "go1.22.txt"))
+	if len(groups) != 4 {
+		t.Fatalf("got %d groups, want 4", len(groups))
+	}
+
+	workers := groups[0] // The largest group first
+	want := models.GoroutineGroup{
+		Count:          2,
+		States:         map[string]int{"chan receive": 2},
+		MinWaitMinutes: 5,
+		MaxWaitMinutes: 12,
+		Frames:         []models.GoroutineFrame{{Function: "main.worker", File: "/app/main.go", Line: 14}},
+		CreatedBy:      &models.GoroutineFrame{Function: "main.main", File: "/app/main.go", Line: 10},
+		IDs:            []int64{7, 8},
+	}
+	if !reflect.DeepEqual(workers, want) {
+		t.Errorf("groups[0] = %+v, want %+v", workers, want)
+	}
+
+	for _, group := range groups[1:] {
+		if group.Count != 1 {
+			t.Errorf("group %v has %d goroutines, want 1", group.IDs, group.Count)
+		}
+	}
+}
+
+func TestGroupGoroutinesByCreationSite(t *testing.T) {
+	frames := []models.GoroutineFrame{{Function: "main.worker", File: "/app/main.go", Line: 14}}
+	goroutines := []models.Goroutine{
+		{ID: 1, State: "select", Frames: frames, CreatedBy: &models.GoroutineFrame{Function: "main.a", File: "/app/main.go", Line: 3}},
+		{ID: 2, State: "select", Frames: frames, CreatedBy: &models.GoroutineFrame{Function: "main.b", File: "/app/main.go", Line: 7}},
+	}
+	if groups := GroupGoroutines(goroutines); len(groups) != 2 {
+		t.Errorf("got %d groups, want the identical stacks of different creation sites apart", len(groups))
+	}
+}
@@ -8,9 +8,9 @@ import (
 	"github.com/iyashjayesh/monigo/models"
 )
 
-// getProcessCPUUsage returns the CPU usage of the process
+// getServiceCPUUsage returns the CPU usage of the process measured by the last collection
 func getServiceCPUUsage() (float64, error) {
-	return common.GetProcessObject().CPUPercent()
+	return lastCPUUsage.servicePercent, nil
 }
 
 // getServiceGoroutines returns the number of goroutines in the service
@@ -38,16 +38,26 @@ func calculateServiceHealth(stats *models.ServiceStats) (float64, string, error)
 		return 0, "", fmt.Errorf("failed to get service CPU usage: %w", err)
 	}
 
-	totalAvailableCores := stats.CPUStatistics.TotalCores
-	cpuUsagePercentage := (cpuUsage / float64(totalAvailableCores)) * 100
+	// Using the container limits when they exist, the service is throttled or OOM-killed at the limits instead of the host capacity
+	var cpuUsagePercentage float64
+	if container := stats.Container.CPU; container.LimitCores > 0 {
+		cpuUsagePercentage = (cpuUsage / 100 / container.LimitCores) * 100
+	} else if totalAvailableCores := stats.CPUStatistics.TotalLogicalCores; totalAvailableCores > 0 {
+		cpuUsagePercentage = cpuUsage / totalAvailableCores // The usage is a percentage of one core, the service can use every logical core
+	}
 
 	// Calculating memory usage percentage for the service
-	memoryUsagePercentage, err := calculateMemoryUsagePercentage(
-		stats.MemoryStatistics.MemoryUsedByService,
-		stats.MemoryStatistics.TotalSystemMemory,
-	)
-	if err != nil {
-		return 0, "", fmt.Errorf("failed to calculate memory usage percentage: %w", err)
+	var memoryUsagePercentage float64
+	if container := stats.Container.Memory; container.LimitBytes > 0 {
+		memoryUsagePercentage = container.UsagePercent
+	} else {
+		memoryUsagePercentage, err = calculateMemoryUsagePercentage(
+			stats.MemoryStatistics.MemoryUsedByService,
+			stats.MemoryStatistics.TotalSystemMemory,
+		)
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to calculate memory usage percentage: %w", err)
+		}
 	}
 
 	// Calculating the health ratios for CPU, memory, and goroutines
@@ -58,7 +68,7 @@ func calculateServiceHealth(stats *models.ServiceStats) (float64, string, error)
 
 	var message string
 	if finalScore > 100 {
-		finalScore = 100
+		finalScore = 0
 		message = fmt.Sprintf(
 			"Service usage exceeds allowed limits: CPU Usage %.2f%% / %.2f%%, Memory Usage %.2f%% / %.2f%%, Goroutines %.2f / %d",
 			cpuUsageRatio, serviceHealthThresholds.MaxCPUUsage,
@@ -75,6 +85,12 @@ func calculateServiceHealth(stats *models.ServiceStats) (float64, string, error)
 		)
 	}
 
+	// Goroutine leaks lower the health before the goroutines reach the limit
+	if penalty, suspects := goroutineLeakPenalty(); suspects > 0 {
+		finalScore = max(finalScore-penalty, 0)
+		message += fmt.Sprintf(", Goroutine leak suspects %d (-%.0f%%)", suspects, penalty)
+	}
+
 	return finalScore, message, nil
 }
 
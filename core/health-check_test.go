@@ -0,0 +1,113 @@
+package core
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+// setHealthInputs sets the thresholds and the CPU usage of the service for the test, restoring them afterwards.
+// The goroutine limit is high enough for the goroutines of the test to weigh nothing in the score.
+func setHealthInputs(t *testing.T, servicePercent float64) {
+	t.Helper()
+	thresholds, usage := serviceHealthThresholds, lastCPUUsage
+	t.Cleanup(func() { serviceHealthThresholds, lastCPUUsage = thresholds, usage })
+
+	serviceHealthThresholds = models.ServiceHealthThresholds{MaxCPUUsage: 100, MaxMemoryUsage: 100, MaxGoRoutines: 1e9}
+	lastCPUUsage = cpuUsage{servicePercent: servicePercent}
+}
+
+func TestCalculateServiceHealth(t *testing.T) {
+	hostMemory := models.MemoryStatistics{MemoryUsedByService: "1.00 GB", TotalSystemMemory: "4.00 GB"} // 25%
+	hostCPU := models.CPUStatistics{TotalCores: 4, TotalLogicalCores: 8}
+
+	tests := []struct {
+		name           string
+		servicePercent float64 // Percentage of one core used by the service
+		stats          models.ServiceStats
+		want           float64
+		exceeds        bool
+	}{
+		{
+			name:           "host",
+			servicePercent: 200,
+			stats:          models.ServiceStats{CPUStatistics: hostCPU, MemoryStatistics: hostMemory},
+			want:           100 - (25+25)/3.0, // 2 of the 8 logical cores
+		},
+		{
+			name:           "host over the limits",
+			servicePercent: 5000,
+			stats:          models.ServiceStats{CPUStatistics: hostCPU, MemoryStatistics: hostMemory},
+			want:           0,
+			exceeds:        true,
+		},
+		{
+			name:           "container limits",
+			servicePercent: 200,
+			stats: models.ServiceStats{
+				CPUStatistics:    hostCPU,
+				MemoryStatistics: hostMemory,
+				Container: models.ContainerStatistics{
+					CPU:    models.ContainerCPU{LimitCores: 4},
+					Memory: models.ContainerMemory{LimitBytes: 1 << 30, UsagePercent: 60},
+				},
+			},
+			want: 100 - (50+60)/3.0,
+		},
+		{
+			name:           "container CPU limit only",
+			servicePercent: 50,
+			stats: models.ServiceStats{
+				CPUStatistics:    hostCPU,
+				MemoryStatistics: hostMemory,
+				Container:        models.ContainerStatistics{CPU: models.ContainerCPU{LimitCores: 1}},
+			},
+			want: 100 - (50+25)/3.0,
+		},
+		{
+			name:           "container over the limits",
+			servicePercent: 300,
+			stats: models.ServiceStats{
+				CPUStatistics:    hostCPU,
+				MemoryStatistics: hostMemory,
+				Container: models.ContainerStatistics{
+					CPU:    models.ContainerCPU{LimitCores: 1},
+					Memory: models.ContainerMemory{LimitBytes: 1 << 30, UsagePercent: 90},
+				},
+			},
+			want:    0,
+			exceeds: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setHealthInputs(t, tt.servicePercent)
+
+			got, message, err := calculateServiceHealth(&tt.stats)
+			if err != nil {
+				t.Fatalf("calculateServiceHealth() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("calculateServiceHealth() = %.2f, want %.2f (%s)", got, tt.want, message)
+			}
+			if exceeds := strings.Contains(message, "exceeds"); exceeds != tt.exceeds {
+				t.Errorf("message = %q, want exceeding the limits %v", message, tt.exceeds)
+			}
+		})
+	}
+}
+
+func TestCalculateServiceHealthInvalidMemory(t *testing.T) {
+	setHealthInputs(t, 0)
+
+	stats := models.ServiceStats{
+		CPUStatistics:    models.CPUStatistics{TotalCores: 4, TotalLogicalCores: 8},
+		MemoryStatistics: models.MemoryStatistics{MemoryUsedByService: "1.00 XB", TotalSystemMemory: "4.00 GB"},
+	}
+	if _, _, err := calculateServiceHealth(&stats); err == nil {
+		t.Error("calculateServiceHealth() error = nil, want the memory parsing error")
+	}
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const (
+	latencyWindowSize = 1024 // Number of recent latency samples kept per route for percentile calculation
+	maxTrackedRoutes  = 256  // Number of routes tracked, the requests of any further route are recorded under OverflowRoute
+
+	UnmatchedRoute = "unmatched" // Route of the requests matching no route, ex. the 404s of a scan
+	OverflowRoute  = "overflow"  // Route of the requests once maxTrackedRoutes routes are tracked
+	OtherMethod    = "OTHER"     // Method of the requests with a non-standard method
+)
+
+// standardMethods are the methods recorded as is, any other method is recorded as OtherMethod.
+var standardMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+var (
+	requestMu            sync.Mutex
+	routeMetrics         = make(map[routeKey]*routeMetric)
+	totalRequestCount    int64
+	totalRequestDuration time.Duration
+)
+
+// routeKey identifies a route served by the service.
+type routeKey struct {
+	Route  string
+	Method string
+}
+
+// routeMetric holds the request statistics of a single route.
+type routeMetric struct {
+	requestCount  int64
+	totalDuration time.Duration
+	statusCodes   map[string]int64
+	latencies     *LatencyWindow
+}
+
+// SampleWindow keeps the most recent samples in a ring buffer.
+type SampleWindow[T cmp.Ordered] struct {
+	samples []T
+	next    int
+	full    bool
+}
+
+// LatencyWindow keeps the most recent latency samples in a ring buffer.
+type LatencyWindow = SampleWindow[time.Duration]
+
+// NewSampleWindow creates a new SampleWindow holding up to size samples.
+func NewSampleWindow[T cmp.Ordered](size int) *SampleWindow[T] {
+	return &SampleWindow[T]{samples: make([]T, size)}
+}
+
+// NewLatencyWindow creates a new LatencyWindow holding up to size samples.
+func NewLatencyWindow(size int) *LatencyWindow {
+	return NewSampleWindow[time.Duration](size)
+}
+
+// Observe adds a sample to the window, overwriting the oldest one once the window is full.
+func (w *SampleWindow[T]) Observe(v T) {
+	w.samples[w.next] = v
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// Percentiles returns the requested percentiles (0-100) of the samples in the window.
+func (w *SampleWindow[T]) Percentiles(percentiles ...float64) []T {
+	n := w.next
+	if w.full {
+		n = len(w.samples)
+	}
+
+	result := make([]T, len(percentiles))
+	if n == 0 {
+		return result
+	}
+
+	sorted := make([]T, n)
+	copy(sorted, w.samples[:n])
+	slices.Sort(sorted)
+
+	for i, p := range percentiles {
+		idx := int(float64(n-1) * p / 100)
+		result[i] = sorted[idx]
+	}
+	return result
+}
+
+// RecordRequest records a request served by the service. An empty route is recorded as UnmatchedRoute and
+// a non-standard method as OtherMethod, so the number of routes tracked and of series stored stays bounded.
+func RecordRequest(route, method string, statusCode int, duration time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	if !standardMethods[method] {
+		method = OtherMethod
+	}
+
+	requestMu.Lock()
+	defer requestMu.Unlock()
+
+	key := routeKey{Route: route, Method: method}
+	metric, ok := routeMetrics[key]
+	if !ok && len(routeMetrics) >= maxTrackedRoutes {
+		key.Route = OverflowRoute
+		metric, ok = routeMetrics[key]
+	}
+	if !ok {
+		metric = &routeMetric{
+			statusCodes: make(map[string]int64),
+			latencies:   NewLatencyWindow(latencyWindowSize),
+		}
+		routeMetrics[key] = metric
+	}
+
+	metric.requestCount++
+	metric.totalDuration += duration
+	metric.statusCodes[statusClass(statusCode)]++
+	metric.latencies.Observe(duration)
+
+	totalRequestCount++
+	totalRequestDuration += duration
+}
+
+// GetRequestCount returns the total number of requests and the total time taken by them.
+func GetRequestCount() (int64, time.Duration) {
+	requestMu.Lock()
+	defer requestMu.Unlock()
+
+	return totalRequestCount, totalRequestDuration
+}
+
+// GetRequestStatistics returns the request statistics of every route served by the service.
+func GetRequestStatistics() []models.RouteStatistics {
+	requestMu.Lock()
+	defer requestMu.Unlock()
+
+	stats := make([]models.RouteStatistics, 0, len(routeMetrics))
+	for key, metric := range routeMetrics {
+		statusCodes := make(map[string]int64, len(metric.statusCodes))
+		for class, count := range metric.statusCodes {
+			statusCodes[class] = count
+		}
+
+		p := metric.latencies.Percentiles(50, 90, 99)
+		stats = append(stats, models.RouteStatistics{
+			Route:           key.Route,
+			Method:          key.Method,
+			RequestCount:    metric.requestCount,
+			StatusCodes:     statusCodes,
+			AvgLatencyMs:    common.RoundFloat64(durationToMs(metric.totalDuration)/float64(metric.requestCount), 3),
+			LatencyP50Ms:    common.RoundFloat64(durationToMs(p[0]), 3),
+			LatencyP90Ms:    common.RoundFloat64(durationToMs(p[1]), 3),
+			LatencyP99Ms:    common.RoundFloat64(durationToMs(p[2]), 3),
+			TotalDurationMs: common.RoundFloat64(durationToMs(metric.totalDuration), 3),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Route == stats[j].Route {
+			return stats[i].Method < stats[j].Method
+		}
+		return stats[i].Route < stats[j].Route
+	})
+
+	return stats
+}
+
+// statusClass returns the status class of the status code, ex. 200 -> "2xx".
+func statusClass(statusCode int) string {
+	if statusCode < 100 || statusCode > 599 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%dxx", statusCode/100)
+}
+
+// durationToMs converts the duration to milliseconds.
+func durationToMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+// resetRequestMetrics clears the recorded requests before and after the test.
+func resetRequestMetrics(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		requestMu.Lock()
+		defer requestMu.Unlock()
+		routeMetrics = make(map[routeKey]*routeMetric)
+		totalRequestCount, totalRequestDuration = 0, 0
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+// routeStatistics returns the statistics of the route and method, failing the test when they were not recorded.
+func routeStatistics(t *testing.T, route, method string) models.RouteStatistics {
+	t.Helper()
+	for _, stats := range GetRequestStatistics() {
+		if stats.Route == route && stats.Method == method {
+			return stats
+		}
+	}
+	t.Fatalf("no statistics recorded for %s %s", method, route)
+	return models.RouteStatistics{}
+}
+
+func TestRecordRequestNormalizesRouteAndMethod(t *testing.T) {
+	resetRequestMetrics(t)
+
+	RecordRequest("", "GET", 404, time.Millisecond)
+	RecordRequest("/users", "PURGE", 200, time.Millisecond)
+	RecordRequest("/users", "BREW", 200, time.Millisecond)
+
+	if got := routeStatistics(t, UnmatchedRoute, "GET").RequestCount; got != 1 {
+		t.Errorf("unmatched request count = %d, want 1", got)
+	}
+	if got := routeStatistics(t, "/users", OtherMethod).RequestCount; got != 2 {
+		t.Errorf("OTHER request count = %d, want 2", got)
+	}
+	if got := len(GetRequestStatistics()); got != 2 {
+		t.Errorf("tracked routes = %d, want 2", got)
+	}
+}
+
+func TestRecordRequestBoundsTrackedRoutes(t *testing.T) {
+	resetRequestMetrics(t)
+
+	for i := 0; i < maxTrackedRoutes+50; i++ {
+		RecordRequest(fmt.Sprintf("/items/%d", i), "GET", 200, time.Millisecond)
+	}
+	RecordRequest("/items/0", "GET", 200, time.Millisecond) // A tracked route is still recorded under its name
+
+	if got := len(GetRequestStatistics()); got != maxTrackedRoutes+1 {
+		t.Errorf("tracked routes = %d, want %d", got, maxTrackedRoutes+1)
+	}
+	if got := routeStatistics(t, OverflowRoute, "GET").RequestCount; got != 50 {
+		t.Errorf("overflow request count = %d, want 50", got)
+	}
+	if got := routeStatistics(t, "/items/0", "GET").RequestCount; got != 2 {
+		t.Errorf("/items/0 request count = %d, want 2", got)
+	}
+
+	count, _ := GetRequestCount()
+	if count != maxTrackedRoutes+51 {
+		t.Errorf("total request count = %d, want %d", count, maxTrackedRoutes+51)
+	}
+}
+
+func TestRecordRequestStatusClasses(t *testing.T) {
+	resetRequestMetrics(t)
+
+	for _, status := range []int{200, 204, 301, 404, 429, 503, 42} {
+		RecordRequest("/status", "GET", status, time.Millisecond)
+	}
+
+	want := map[string]int64{"2xx": 2, "3xx": 1, "4xx": 2, "5xx": 1, "unknown": 1}
+	if got := routeStatistics(t, "/status", "GET").StatusCodes; !maps.Equal(got, want) {
+		t.Errorf("status codes = %v, want %v", got, want)
+	}
+}
+
+func TestRecordRequestLatencies(t *testing.T) {
+	resetRequestMetrics(t)
+
+	for i := 100; i >= 1; i-- { // Recorded out of order, the percentiles are calculated on the sorted latencies
+		RecordRequest("/latency", "GET", 200, time.Duration(i)*time.Millisecond)
+	}
+
+	got := routeStatistics(t, "/latency", "GET")
+	want := models.RouteStatistics{
+		Route:           "/latency",
+		Method:          "GET",
+		RequestCount:    100,
+		AvgLatencyMs:    50.5,
+		LatencyP50Ms:    50,
+		LatencyP90Ms:    90,
+		LatencyP99Ms:    99,
+		TotalDurationMs: 5050,
+	}
+	got.StatusCodes = nil // Checked by TestRecordRequestStatusClasses
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("statistics = %+v, want %+v", got, want)
+	}
+
+	count, duration := GetRequestCount()
+	if count != 100 || duration != 5050*time.Millisecond {
+		t.Errorf("GetRequestCount() = %d, %v, want 100, 5.05s", count, duration)
+	}
+}
+
+func TestSampleWindowPercentiles(t *testing.T) {
+	w := NewSampleWindow[float64](4)
+	if got := w.Percentiles(50); got[0] != 0 {
+		t.Errorf("percentile of an empty window = %v, want 0", got[0])
+	}
+
+	for i := 1; i <= 6; i++ { // The window keeps 3, 4, 5 and 6
+		w.Observe(float64(i))
+	}
+	got := w.Percentiles(0, 50, 100)
+	if want := []float64{3, 4, 6}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Percentiles(0, 50, 100) = %v, want %v", got, want)
+	}
+}
@@ -1,16 +1,12 @@
 package core
 
 import (
-	"log"
-	"runtime"
+	"fmt"
 	"sync"
-	"time"
 
 	"github.com/iyashjayesh/monigo/common"
 	"github.com/iyashjayesh/monigo/models"
-	"github.com/shirou/gopsutil/cpu"
 	"github.com/shirou/gopsutil/mem"
-	"github.com/shirou/gopsutil/process"
 )
 
 var (
@@ -18,46 +14,19 @@ var (
 	serviceHealthThresholds models.ServiceHealthThresholds
 )
 
-// GetRequestCount returns the total number of requests
+// GetCPUPrecent returns the CPU usage percentage of the system measured by the last collection
 func GetCPUPrecent() float64 {
-	cpuPercents, err := cpu.Percent(time.Second, false)
-	if err != nil {
-		log.Panicf("[MoniGo] Error fetching CPU usage: %v\n", err)
-		return 0
-	}
-
-	var total float64
-	for _, percent := range cpuPercents {
-		total += percent
-	}
-
-	return total / float64(len(cpuPercents))
+	return lastCPUUsage.systemPercent
 }
 
 // GetVirtualMemoryStats returns the virtual memory statistics
-func GetVirtualMemoryStats() mem.VirtualMemoryStat {
+func GetVirtualMemoryStats() (mem.VirtualMemoryStat, error) {
 	memInfo, err := mem.VirtualMemory()
 	if err != nil {
-		log.Panicf("[MoniGo] Error fetching memory usage: %v\n", err)
-		return mem.VirtualMemoryStat{}
-	}
-
-	return *memInfo
-}
-
-// Fetches and returns process CPU and memory usage
-func getProcessUsage(proc *process.Process, memsStats *mem.VirtualMemoryStat) (float64, float64, error) {
-	procCPUPercent, err := proc.CPUPercent()
-	if err != nil {
-		return 0, 0, err
+		return mem.VirtualMemoryStat{}, fmt.Errorf("error fetching memory usage: %w", err)
 	}
 
-	memStats := ReadMemStats()
-
-	// Calculate memory used by the process as a percentage of total system memory
-	processMemPercent := (float64(memStats.Alloc) / float64(memsStats.Total)) * 100
-
-	return procCPUPercent, processMemPercent, nil
+	return *memInfo, nil
 }
 
 // SetServiceThresholds sets the service thresholds to calculate the overall service health.
@@ -91,10 +60,3 @@ func newRecord(name, description string, value interface{}) models.Record {
 		}
 	}
 }
-
-// ReadMemStats reads and returns the memory statistics
-func ReadMemStats() *runtime.MemStats {
-	memStats := runtime.MemStats{}
-	runtime.ReadMemStats(&memStats)
-	return &memStats
-}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/iyashjayesh/monigo/models"
+	"github.com/shirou/gopsutil/net"
+)
+
+var DefaultExcludedInterfaces = []string{"lo", "lo0"} // Loopback interfaces on Linux and macOS
+
+var (
+	excludedInterfaces = DefaultExcludedInterfaces // Guarded by collectMu
+	lastNetworkIO      networkIOCounters           // Counters read by the previous collection, guarded by collectMu
+)
+
+// networkIOCounters holds the network I/O counters of the interfaces at a point in time.
+type networkIOCounters struct {
+	at         time.Time
+	interfaces map[string]net.IOCountersStat
+}
+
+// SetExcludedInterfaces sets the interfaces left out of the network I/O, ex. "docker0" or "veth*".
+// The names are matched as filepath.Match patterns, nil restores DefaultExcludedInterfaces and an empty list excludes none.
+func SetExcludedInterfaces(patterns []string) {
+	collectMu.Lock()
+	defer collectMu.Unlock()
+
+	if patterns == nil {
+		patterns = DefaultExcludedInterfaces
+	}
+	excludedInterfaces = append([]string{}, patterns...)
+}
+
+// GetNetworkIO reads the network I/O of every interface that is not excluded, measuring the rates since the previous collection.
+// It must be called with collectMu held.
+func GetNetworkIO() (models.NetworkIOStatistics, error) {
+	var stats models.NetworkIOStatistics
+
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return stats, fmt.Errorf("error fetching network I/O statistics: %w", err)
+	}
+
+	current := networkIOCounters{at: time.Now(), interfaces: make(map[string]net.IOCountersStat)}
+	for _, iface := range counters {
+		if !isExcludedInterface(iface.Name) {
+			current.interfaces[iface.Name] = iface
+		}
+	}
+
+	previous := lastNetworkIO
+	elapsed := current.at.Sub(previous.at).Seconds()
+	if previous.at.IsZero() {
+		elapsed = 0 // No rates on the first collection
+	}
+
+	for name, c := range current.interfaces {
+		iface := models.InterfaceIO{
+			Name:            name,
+			BytesSent:       c.BytesSent,
+			BytesReceived:   c.BytesRecv,
+			PacketsSent:     c.PacketsSent,
+			PacketsReceived: c.PacketsRecv,
+			ErrorsIn:        c.Errin,
+			ErrorsOut:       c.Errout,
+			DropsIn:         c.Dropin,
+			DropsOut:        c.Dropout,
+		}
+		if prev, ok := previous.interfaces[name]; ok && elapsed > 0 {
+			iface.BytesSentPerSec = counterRate(prev.BytesSent, c.BytesSent, elapsed)
+			iface.BytesReceivedPerSec = counterRate(prev.BytesRecv, c.BytesRecv, elapsed)
+		}
+
+		stats.BytesSent += float64(iface.BytesSent)
+		stats.BytesReceived += float64(iface.BytesReceived)
+		stats.BytesSentPerSec += iface.BytesSentPerSec
+		stats.BytesReceivedPerSec += iface.BytesReceivedPerSec
+		stats.Interfaces = append(stats.Interfaces, iface)
+	}
+	sort.Slice(stats.Interfaces, func(i, j int) bool { return stats.Interfaces[i].Name < stats.Interfaces[j].Name })
+
+	lastNetworkIO = current
+	return stats, nil
+}
+
+// isExcludedInterface returns whether the interface matches one of the excluded patterns.
+func isExcludedInterface(name string) bool {
+	for _, pattern := range excludedInterfaces {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
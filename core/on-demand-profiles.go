@@ -0,0 +1,415 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/models"
+)
+
+// Sources of the stored profiles.
+const (
+	ProfileSourceOnDemand = "on-demand" // Captured through CaptureProfile
+	ProfileSourceArchive  = "archive"   // Captured by the continuous profiler
+)
+
+// Kinds of the profiles captured on demand.
+const (
+	ProfileCPU       = "cpu"
+	ProfileHeap      = "heap"
+	ProfileAllocs    = "allocs"
+	ProfileBlock     = "block"
+	ProfileMutex     = "mutex"
+	ProfileGoroutine = "goroutine"
+	ProfileTrace     = "trace" // Execution trace of runtime/trace, read with go tool trace
+)
+
+const (
+	DefaultCaptureDuration = 30 * time.Second // Duration of the CPU profiles and the execution traces when none is given
+	MaxCaptureDuration     = 5 * time.Minute
+	onDemandProfilesKept   = 100 // Number of on-demand profiles kept, the oldest are removed
+)
+
+var (
+	ErrProfilerBusy   = errors.New("the profiler is busy")    // Another capture needing the same profiler is running
+	ErrInvalidCapture = errors.New("invalid capture request") // The kind, duration or rate of the capture is invalid
+)
+
+var (
+	traceMu       sync.Mutex // Held while an execution trace is captured
+	blockRateMu   sync.Mutex // Held while the block profile rate is set by a capture
+	mutexRateMu   sync.Mutex // Held while the mutex profile fraction is set by a capture
+	onDemandMu    sync.Mutex // Guards the metadata files and the retention of the on-demand profiles
+	profileIDExpr = regexp.MustCompile(`^[a-z]+-[0-9]+$`)
+)
+
+// blockProfileRate is the block profile rate of the service, restored after a capture setting another rate.
+// The runtime does not expose the rate in use, so it is the rate set through SetBlockProfileRate.
+var blockProfileRate struct {
+	sync.Mutex
+	rate      int
+	capturing bool // The rate in use is the rate of a capture, the rate of the service is applied once it ends
+}
+
+// CaptureRequest describes a profile to capture on demand.
+type CaptureRequest struct {
+	Kind     string        // One of the profile kinds, ex. ProfileCPU
+	Duration time.Duration // Duration of a CPU profile or an execution trace. A block or mutex profile then holds the delta over the duration, else the totals since the start
+	Rate     int           // Block profile rate in nanoseconds or mutex profile fraction set for the duration of the capture
+	GC       bool          // Run a garbage collection before a heap or allocs profile, so it is up to date
+}
+
+// CaptureProfile captures a profile and stores it under the profiles folder along with its metadata.
+// The capture stops early, without storing the profile, when the context is done.
+func CaptureProfile(ctx context.Context, request CaptureRequest) (models.ProfileMetadata, error) {
+	request, err := validateCaptureRequest(request)
+	if err != nil {
+		return models.ProfileMetadata{}, err
+	}
+
+	metadata, err := captureProfile(ctx, request, onDemandProfilesPath(), ProfileSourceOnDemand)
+	if err != nil {
+		return metadata, err
+	}
+	if err := storeProfileMetadata(metadata); err != nil {
+		os.Remove(metadata.Path)
+		return metadata, err
+	}
+	return metadata, nil
+}
+
+// captureProfile captures a validated request into a file of the folder, named after the kind and the capture time.
+func captureProfile(ctx context.Context, request CaptureRequest, folderPath, source string) (models.ProfileMetadata, error) {
+	if err := os.MkdirAll(folderPath, os.ModePerm); err != nil {
+		return models.ProfileMetadata{}, fmt.Errorf("error creating the profiles folder: %w", err)
+	}
+
+	capturedAt := time.Now()
+	metadata := models.ProfileMetadata{
+		ID:              fmt.Sprintf("%s-%d", request.Kind, capturedAt.UnixNano()),
+		Kind:            request.Kind,
+		Source:          source,
+		CapturedAt:      capturedAt,
+		DurationSeconds: request.Duration.Seconds(),
+		Rate:            request.Rate,
+	}
+	metadata.FileName = metadata.ID + ".pb.gz"
+	if request.Kind == ProfileTrace {
+		metadata.FileName = metadata.ID + ".trace"
+	}
+	metadata.Path = filepath.Join(folderPath, metadata.FileName)
+
+	f, err := os.Create(metadata.Path)
+	if err != nil {
+		return metadata, fmt.Errorf("error creating the profile file: %w", err)
+	}
+	err = writeProfile(ctx, f, request)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(metadata.Path)
+		return metadata, err
+	}
+
+	if info, err := os.Stat(metadata.Path); err == nil {
+		metadata.SizeBytes = info.Size()
+	}
+	return metadata, nil
+}
+
+// validateCaptureRequest checks the kind and duration of the request, and sets the default duration.
+func validateCaptureRequest(request CaptureRequest) (CaptureRequest, error) {
+	switch request.Kind {
+	case ProfileCPU, ProfileTrace:
+		if request.Duration == 0 {
+			request.Duration = DefaultCaptureDuration
+		}
+	case ProfileBlock, ProfileMutex:
+		if request.Duration == 0 && request.Rate > 0 {
+			request.Duration = DefaultCaptureDuration // Setting the rate is only meaningful over a duration
+		}
+	case ProfileHeap, ProfileAllocs, ProfileGoroutine:
+		request.Duration = 0
+	default:
+		return request, fmt.Errorf("%w: unknown profile kind %q", ErrInvalidCapture, request.Kind)
+	}
+
+	if request.Duration < 0 || request.Duration > MaxCaptureDuration {
+		return request, fmt.Errorf("%w: the duration must be between 0 and %s", ErrInvalidCapture, MaxCaptureDuration)
+	}
+	if request.Rate < 0 {
+		return request, fmt.Errorf("%w: the rate must not be negative", ErrInvalidCapture)
+	}
+	return request, nil
+}
+
+// writeProfile captures the profile into the file.
+func writeProfile(ctx context.Context, f *os.File, request CaptureRequest) error {
+	switch request.Kind {
+	case ProfileCPU:
+		if !profileMu.TryLock() {
+			return fmt.Errorf("%w: a CPU profile is being captured", ErrProfilerBusy)
+		}
+		defer profileMu.Unlock()
+
+		if err := pprof.StartCPUProfile(f); err != nil { // The CPU profiler may be in use outside of monigo
+			return fmt.Errorf("%w: %v", ErrProfilerBusy, err)
+		}
+		err := waitCapture(ctx, request.Duration)
+		pprof.StopCPUProfile()
+		return err
+
+	case ProfileTrace:
+		if !traceMu.TryLock() {
+			return fmt.Errorf("%w: an execution trace is being captured", ErrProfilerBusy)
+		}
+		defer traceMu.Unlock()
+
+		if err := trace.Start(f); err != nil {
+			return fmt.Errorf("%w: %v", ErrProfilerBusy, err)
+		}
+		err := waitCapture(ctx, request.Duration)
+		trace.Stop()
+		return err
+
+	case ProfileBlock, ProfileMutex:
+		if request.Rate > 0 {
+			restore, err := setProfileRate(request.Kind, request.Rate)
+			if err != nil {
+				return err
+			}
+			defer restore()
+		}
+		if request.Duration == 0 {
+			return pprof.Lookup(request.Kind).WriteTo(f, 0)
+		}
+
+		p, err := deltaProfile(ctx, request.Kind, request.Duration)
+		if err != nil {
+			return err
+		}
+		return p.Write(f)
+
+	default: // Heap, allocs and goroutine snapshots
+		if request.GC {
+			runtime.GC()
+		}
+		return pprof.Lookup(request.Kind).WriteTo(f, 0)
+	}
+}
+
+// SetBlockProfileRate sets the block profile rate like runtime.SetBlockProfileRate, and records it so the
+// rate is restored after a block profile captured with another rate.
+func SetBlockProfileRate(rate int) {
+	blockProfileRate.Lock()
+	defer blockProfileRate.Unlock()
+
+	blockProfileRate.rate = rate
+	if !blockProfileRate.capturing {
+		runtime.SetBlockProfileRate(rate)
+	}
+}
+
+// setProfileRate sets the block profile rate or the mutex profile fraction, returning the function restoring it.
+// The block profile rate is restored to the rate set through SetBlockProfileRate, disabled by default.
+func setProfileRate(kind string, rate int) (func(), error) {
+	if kind == ProfileBlock {
+		if !blockRateMu.TryLock() {
+			return nil, fmt.Errorf("%w: the block profile rate is set by another capture", ErrProfilerBusy)
+		}
+		blockProfileRate.Lock()
+		blockProfileRate.capturing = true
+		runtime.SetBlockProfileRate(rate)
+		blockProfileRate.Unlock()
+		return func() {
+			blockProfileRate.Lock()
+			blockProfileRate.capturing = false
+			runtime.SetBlockProfileRate(blockProfileRate.rate)
+			blockProfileRate.Unlock()
+			blockRateMu.Unlock()
+		}, nil
+	}
+
+	if !mutexRateMu.TryLock() {
+		return nil, fmt.Errorf("%w: the mutex profile fraction is set by another capture", ErrProfilerBusy)
+	}
+	previous := runtime.SetMutexProfileFraction(rate)
+	return func() {
+		runtime.SetMutexProfileFraction(previous)
+		mutexRateMu.Unlock()
+	}, nil
+}
+
+// deltaProfile returns the difference of a cumulative profile over the duration, as net/http/pprof does with the seconds parameter.
+func deltaProfile(ctx context.Context, kind string, duration time.Duration) (*profile.Profile, error) {
+	before, err := lookupProfile(kind)
+	if err != nil {
+		return nil, err
+	}
+	if err := waitCapture(ctx, duration); err != nil {
+		return nil, err
+	}
+	after, err := lookupProfile(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	before.Scale(-1)
+	delta, err := profile.Merge([]*profile.Profile{before, after}) // The samples without a change are dropped
+	if err != nil {
+		return nil, fmt.Errorf("error computing the %s profile delta: %w", kind, err)
+	}
+	delta.TimeNanos = after.TimeNanos
+	delta.DurationNanos = duration.Nanoseconds()
+	return delta, nil
+}
+
+// lookupProfile returns the decoded runtime profile of the kind.
+func lookupProfile(kind string) (*profile.Profile, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup(kind).WriteTo(&buf, 0); err != nil {
+		return nil, fmt.Errorf("error writing the %s profile: %w", kind, err)
+	}
+	return profile.Parse(&buf)
+}
+
+// waitCapture waits for the duration of a capture, returning the error of the context when it is done before.
+func waitCapture(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// onDemandProfilesPath returns the folder of the profiles captured on demand.
+func onDemandProfilesPath() string {
+	return filepath.Join(common.GetBasePath(), "profiles", "on-demand")
+}
+
+// storeProfileMetadata writes the metadata next to the profile, and removes the oldest profiles exceeding onDemandProfilesKept.
+func storeProfileMetadata(metadata models.ProfileMetadata) error {
+	onDemandMu.Lock()
+	defer onDemandMu.Unlock()
+
+	if err := writeProfileMetadata(onDemandProfilesPath(), metadata); err != nil {
+		return err
+	}
+
+	profiles, err := listProfiles()
+	if err != nil {
+		return err
+	}
+	for _, stale := range profiles[min(onDemandProfilesKept, len(profiles)):] {
+		os.Remove(stale.Path)
+		os.Remove(filepath.Join(onDemandProfilesPath(), stale.ID+".json"))
+	}
+	return nil
+}
+
+// writeProfileMetadata writes the metadata of a profile next to it in the folder.
+func writeProfileMetadata(folderPath string, metadata models.ProfileMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("error encoding the profile metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(folderPath, metadata.ID+".json"), data, 0644); err != nil {
+		return fmt.Errorf("error writing the profile metadata: %w", err)
+	}
+	return nil
+}
+
+// ListProfiles returns the metadata of the profiles captured on demand, the most recent first.
+func ListProfiles() ([]models.ProfileMetadata, error) {
+	onDemandMu.Lock()
+	defer onDemandMu.Unlock()
+
+	return listProfiles()
+}
+
+// listProfiles reads the metadata of the on-demand profiles, it must be called with onDemandMu held.
+func listProfiles() ([]models.ProfileMetadata, error) {
+	return readProfilesMetadata(onDemandProfilesPath())
+}
+
+// readProfilesMetadata reads the metadata of the profiles stored in the folder, the most recent first.
+func readProfilesMetadata(folderPath string) ([]models.ProfileMetadata, error) {
+	entries, err := os.ReadDir(folderPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []models.ProfileMetadata{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error listing the profiles: %w", err)
+	}
+
+	profiles := []models.ProfileMetadata{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		metadata, err := readProfileMetadata(filepath.Join(folderPath, entry.Name()))
+		if err != nil {
+			continue // A profile being removed
+		}
+		profiles = append(profiles, metadata)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].CapturedAt.After(profiles[j].CapturedAt) })
+	return profiles, nil
+}
+
+// GetProfile returns the metadata of a profile captured on demand or archived by the continuous profiler,
+// ErrProfileNotFound is returned when it does not exist.
+func GetProfile(id string) (models.ProfileMetadata, error) {
+	if !profileIDExpr.MatchString(id) { // The ID is part of a path
+		return models.ProfileMetadata{}, fmt.Errorf("%w: %s", ErrProfileNotFound, id)
+	}
+
+	onDemandMu.Lock()
+	metadata, err := readProfileMetadata(filepath.Join(onDemandProfilesPath(), id+".json"))
+	onDemandMu.Unlock()
+	if !errors.Is(err, fs.ErrNotExist) {
+		return metadata, err
+	}
+
+	archiveMu.Lock()
+	metadata, err = readProfileMetadata(filepath.Join(profileArchivePath(), id+".json"))
+	archiveMu.Unlock()
+	if errors.Is(err, fs.ErrNotExist) {
+		return metadata, fmt.Errorf("%w: %s", ErrProfileNotFound, id)
+	}
+	return metadata, err
+}
+
+// readProfileMetadata reads a metadata file, the path of the profile is set from the current profiles folder.
+func readProfileMetadata(path string) (models.ProfileMetadata, error) {
+	var metadata models.ProfileMetadata
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return metadata, err
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return metadata, fmt.Errorf("error decoding the profile metadata %s: %w", path, err)
+	}
+	metadata.Path = filepath.Join(filepath.Dir(path), metadata.FileName)
+	return metadata, nil
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const (
+	DefaultArchiveInterval    = time.Minute       // Time between two captures of the continuous profiler when none is given
+	DefaultArchiveCPUDuration = 10 * time.Second  // Duration of the archived CPU profiles when none is given
+	DefaultArchiveMaxSize     = int64(256 << 20)  // Size of the profile archive when none is given, 256 MiB
+	minArchiveInterval        = 10 * time.Second  // The captures are kept short and infrequent to keep the overhead low
+	archiveFolder             = "profile-archive" // Folder of the archive, next to the data folder of the storage
+)
+
+// ArchiveConfig configures the continuous profiler.
+type ArchiveConfig struct {
+	Interval    time.Duration // Time between two captures, a CPU profile and a heap profile are captured every interval
+	CPUDuration time.Duration // Duration of the CPU profiles, capped to half of the interval
+	MaxSize     int64         // Size of the archive in bytes, the oldest profiles are removed beyond it
+}
+
+var (
+	archiveMu      sync.Mutex               // Guards the archive index and the archive files
+	archiveIndex   []models.ProfileMetadata // Archived profiles, the oldest first
+	archiveLoaded  bool                     // Whether the index has been read from the archive folder
+	archiveSize    int64                    // Size of the archived profiles in bytes
+	archiveMaxSize = DefaultArchiveMaxSize  // Size the archive is pruned to
+	archiveCancel  context.CancelFunc       // Cancel function for the continuous profiler goroutine
+	archiveWg      sync.WaitGroup           // Waits for the continuous profiler goroutine to stop
+	archiveLastErr string                   // Last capture error logged, the same error is not logged again on every capture
+)
+
+// StartContinuousProfiler captures a short CPU profile and a heap profile every interval in the background and keeps them
+// in the profile archive, indexed by capture time. The archive is pruned to the retention period and to its maximum size.
+// A CPU profile is skipped when another one is running, ex. of a traced function or an on-demand capture.
+func StartContinuousProfiler(config ArchiveConfig) {
+	StopContinuousProfiler() // Stopping the previous profiler, if any
+
+	if config.Interval <= 0 {
+		config.Interval = DefaultArchiveInterval
+	}
+	config.Interval = max(config.Interval, minArchiveInterval)
+	if config.CPUDuration <= 0 {
+		config.CPUDuration = DefaultArchiveCPUDuration
+	}
+	config.CPUDuration = min(config.CPUDuration, config.Interval/2)
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultArchiveMaxSize
+	}
+
+	archiveMu.Lock()
+	archiveMaxSize = config.MaxSize
+	archiveLoaded = false // The archive may have been purged since it was read
+	loadProfileArchive()
+	pruneProfileArchive()
+	archiveLastErr = ""
+
+	var ctx context.Context
+	ctx, archiveCancel = context.WithCancel(context.Background())
+	archiveMu.Unlock()
+
+	archiveWg.Add(1)
+	go func() {
+		defer archiveWg.Done()
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			captureArchiveProfiles(ctx, config)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StopContinuousProfiler stops the continuous profiler and waits for it to return, a CPU profile being captured is dropped.
+func StopContinuousProfiler() {
+	archiveMu.Lock()
+	if archiveCancel != nil {
+		archiveCancel()
+		archiveCancel = nil
+	}
+	archiveMu.Unlock()
+
+	archiveWg.Wait()
+}
+
+// captureArchiveProfiles captures a CPU profile and a heap profile into the archive.
+// The heap profile is not preceded by a garbage collection, it holds the allocations as of the last one.
+func captureArchiveProfiles(ctx context.Context, config ArchiveConfig) {
+	for _, request := range []CaptureRequest{
+		{Kind: ProfileCPU, Duration: config.CPUDuration},
+		{Kind: ProfileHeap},
+	} {
+		metadata, err := captureProfile(ctx, request, profileArchivePath(), ProfileSourceArchive)
+		if ctx.Err() != nil {
+			return // Stopped during the capture
+		}
+		if err == nil {
+			err = archiveProfile(metadata)
+		}
+		logArchiveError(request.Kind, err)
+	}
+}
+
+// archiveProfile writes the metadata of a captured profile, adds it to the index and prunes the archive.
+func archiveProfile(metadata models.ProfileMetadata) error {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	if err := writeProfileMetadata(profileArchivePath(), metadata); err != nil {
+		os.Remove(metadata.Path)
+		return err
+	}
+	loadProfileArchive()
+	archiveIndex = append(archiveIndex, metadata)
+	archiveSize += metadata.SizeBytes
+	pruneProfileArchive()
+	return nil
+}
+
+// logArchiveError logs a capture error once until a different error occurs or a capture succeeds.
+func logArchiveError(kind string, err error) {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	if err == nil {
+		archiveLastErr = ""
+		return
+	}
+	if message := kind + ": " + err.Error(); message != archiveLastErr {
+		archiveLastErr = message
+		if errors.Is(err, ErrProfilerBusy) {
+			log.Printf("[MoniGo] Continuous profiler skipped the %s profile, %v\n", kind, err)
+		} else {
+			log.Printf("[MoniGo] Continuous profiler failed to capture the %s profile: %v\n", kind, err)
+		}
+	}
+}
+
+// loadProfileArchive reads the index from the archive folder once, it must be called with archiveMu held.
+// The profiles of the previous runs are kept when the data is persisted.
+func loadProfileArchive() {
+	if archiveLoaded {
+		return
+	}
+
+	profiles, err := readProfilesMetadata(profileArchivePath())
+	if err != nil {
+		log.Println("[MoniGo] error reading the profile archive: ", err)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].CapturedAt.Before(profiles[j].CapturedAt) })
+
+	archiveIndex, archiveSize = profiles, 0
+	for _, metadata := range profiles {
+		archiveSize += metadata.SizeBytes
+	}
+	archiveLoaded = true
+}
+
+// pruneProfileArchive removes the profiles older than the retention period and the oldest profiles exceeding the maximum size,
+// it must be called with archiveMu held.
+func pruneProfileArchive() {
+	cutoff := time.Now().Add(-common.GetDataRetentionPeriod())
+
+	removed := 0
+	for removed < len(archiveIndex) && (archiveIndex[removed].CapturedAt.Before(cutoff) || archiveSize > archiveMaxSize) {
+		stale := archiveIndex[removed]
+		os.Remove(stale.Path)
+		os.Remove(filepath.Join(profileArchivePath(), stale.ID+".json"))
+		archiveSize -= stale.SizeBytes
+		removed++
+	}
+	archiveIndex = archiveIndex[removed:]
+}
+
+// ListArchivedProfiles returns the archived profiles of the kind captured between the start and end times, the most recent first.
+// An empty kind returns every kind and zero times leave the range open.
+func ListArchivedProfiles(kind string, startTime, endTime time.Time) []models.ProfileMetadata {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	loadProfileArchive()
+	profiles := []models.ProfileMetadata{}
+	for i := len(archiveIndex) - 1; i >= 0; i-- {
+		metadata := archiveIndex[i]
+		if (kind == "" || metadata.Kind == kind) &&
+			(startTime.IsZero() || !metadata.CapturedAt.Before(startTime)) &&
+			(endTime.IsZero() || !metadata.CapturedAt.After(endTime)) {
+			profiles = append(profiles, metadata)
+		}
+	}
+	return profiles
+}
+
+// NearestArchivedProfiles returns, for each kind, the archived profile captured the closest to the time,
+// ex. the CPU profile showing what the service was doing when its health dropped.
+func NearestArchivedProfiles(at time.Time) []models.ProfileMetadata {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+
+	loadProfileArchive()
+	nearest := make(map[string]models.ProfileMetadata)
+	distance := func(metadata models.ProfileMetadata) time.Duration {
+		start := metadata.CapturedAt
+		end := start.Add(time.Duration(metadata.DurationSeconds * float64(time.Second)))
+		switch {
+		case at.Before(start):
+			return start.Sub(at)
+		case at.After(end):
+			return at.Sub(end)
+		default:
+			return 0 // The profile was being captured at that time
+		}
+	}
+	for _, metadata := range archiveIndex {
+		if current, ok := nearest[metadata.Kind]; !ok || distance(metadata) < distance(current) {
+			nearest[metadata.Kind] = metadata
+		}
+	}
+
+	profiles := []models.ProfileMetadata{}
+	for _, metadata := range nearest {
+		profiles = append(profiles, metadata)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Kind < profiles[j].Kind })
+	return profiles
+}
+
+// profileArchivePath returns the folder of the profile archive.
+func profileArchivePath() string {
+	return filepath.Join(common.GetBasePath(), archiveFolder)
+}
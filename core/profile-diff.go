@@ -0,0 +1,157 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/pprof/profile"
+	"github.com/iyashjayesh/monigo/models"
+)
+
+var ErrExecutionTrace = errors.New("execution traces are not pprof profiles") // The profile is an execution trace, read with go tool trace
+
+// ProfileDiffOptions configures the difference between two profiles.
+type ProfileDiffOptions struct {
+	SampleType      string // Sample type compared, ex. "alloc_space". Defaults to the default sample type of the target profile
+	Focus           string // Only the samples with a function starting with the prefix are compared, rooted at it in the flame graph
+	CollapseRuntime bool   // Consecutive runtime frames of the flame graph are collapsed into a single frame
+	Normalize       bool   // The base is scaled to the total of the target, ex. to compare CPU profiles of different durations
+	Limit           int    // Number of functions reported, DefaultProfileReportLimit when not set
+}
+
+// ResolveProfile returns the path of a stored profile, referenced by the id of an on-demand or archived profile
+// or by the CPUProfileFilePath or MemProfileFilePath of a profiled call kept for a traced function.
+// Any other path is rejected with ErrProfileNotFound, so only the profiles recorded by monigo can be read.
+func ResolveProfile(reference string) (string, error) {
+	if profileIDExpr.MatchString(reference) {
+		metadata, err := GetProfile(reference)
+		if err != nil {
+			return "", err
+		}
+		if metadata.Kind == ProfileTrace {
+			return "", fmt.Errorf("%w: %s", ErrExecutionTrace, reference)
+		}
+		return metadata.Path, nil
+	}
+
+	if reference != "" && isFunctionProfile(reference) {
+		return reference, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrProfileNotFound, reference)
+}
+
+// DiffProfiles compares the target profile to the base profile, like go tool pprof -diff_base, and returns the change
+// of every function along with a differential flame graph. Both profiles must have the sample type compared.
+func DiffProfiles(basePath, targetPath string, options ProfileDiffOptions) (models.ProfileDiff, error) {
+	diff := models.ProfileDiff{Base: basePath, Target: targetPath, Normalized: options.Normalize}
+
+	base, err := ReadProfile(basePath)
+	if err != nil {
+		return diff, err
+	}
+	target, err := ReadProfile(targetPath)
+	if err != nil {
+		return diff, err
+	}
+
+	targetIndex, err := sampleIndex(target, options.SampleType)
+	if err != nil {
+		return diff, err
+	}
+	diff.SampleType = target.SampleType[targetIndex].Type
+	diff.Unit = target.SampleType[targetIndex].Unit
+
+	baseIndex, err := sampleIndex(base, diff.SampleType)
+	if err != nil {
+		return diff, fmt.Errorf("base profile: %w", err)
+	}
+	if unit := base.SampleType[baseIndex].Unit; unit != diff.Unit {
+		return diff, fmt.Errorf("%w: %s is in %s in the base profile and in %s in the target profile", ErrUnknownSampleType, diff.SampleType, unit, diff.Unit)
+	}
+
+	baseSamples, targetSamples := focusSamples(base.Sample, options.Focus), focusSamples(target.Sample, options.Focus)
+	diff.BaseTotal, diff.TargetTotal = samplesTotal(baseSamples, baseIndex), samplesTotal(targetSamples, targetIndex)
+	if options.Normalize && diff.BaseTotal != 0 && diff.TargetTotal != 0 {
+		base.Scale(float64(diff.TargetTotal) / float64(diff.BaseTotal)) // The samples rounded to zero are dropped
+		baseSamples = focusSamples(base.Sample, options.Focus)
+		diff.BaseTotal = samplesTotal(baseSamples, baseIndex)
+	}
+
+	limit := options.Limit
+	if limit <= 0 {
+		limit = DefaultProfileReportLimit
+	}
+	diff.Functions = functionDeltas(functionValues(baseSamples, baseIndex), functionValues(targetSamples, targetIndex), diff.BaseTotal, limit)
+
+	root := newFlameNode("root")
+	flameOptions := FlameGraphOptions{Focus: options.Focus, CollapseRuntime: options.CollapseRuntime}
+	for _, s := range target.Sample {
+		if stack := flameStack(sampleFrames(s), flameOptions); stack != nil {
+			root.add(stack, s.Value[targetIndex], false)
+		}
+	}
+	for _, s := range base.Sample {
+		if stack := flameStack(sampleFrames(s), flameOptions); stack != nil {
+			root.add(stack, s.Value[baseIndex], true)
+		}
+	}
+	diff.FlameGraph = root.diffModel()
+	return diff, nil
+}
+
+// samplesTotal returns the sum of the values of the samples.
+func samplesTotal(samples []*profile.Sample, index int) int64 {
+	var total int64
+	for _, s := range samples {
+		total += s.Value[index]
+	}
+	return total
+}
+
+// functionDeltas returns the change of the functions found in either profile, the largest changes of their own value first.
+func functionDeltas(base, target map[string]*models.ProfileFunction, baseTotal int64, limit int) []models.ProfileFunctionDelta {
+	deltas := make(map[string]*models.ProfileFunctionDelta)
+	get := func(function *models.ProfileFunction) *models.ProfileFunctionDelta {
+		if _, ok := deltas[function.Function]; !ok {
+			deltas[function.Function] = &models.ProfileFunctionDelta{Function: function.Function, File: function.File}
+		}
+		return deltas[function.Function]
+	}
+	for _, function := range base {
+		delta := get(function)
+		delta.BaseFlat, delta.BaseCum = function.Flat, function.Cum
+	}
+	for _, function := range target {
+		delta := get(function)
+		delta.TargetFlat, delta.TargetCum = function.Flat, function.Cum
+	}
+
+	functions := make([]models.ProfileFunctionDelta, 0, len(deltas))
+	for _, delta := range deltas {
+		delta.FlatDelta = delta.TargetFlat - delta.BaseFlat
+		delta.CumDelta = delta.TargetCum - delta.BaseCum
+		delta.FlatDeltaPercent = percentOf(delta.FlatDelta, baseTotal)
+		delta.CumDeltaPercent = percentOf(delta.CumDelta, baseTotal)
+		if delta.FlatDelta != 0 || delta.CumDelta != 0 {
+			functions = append(functions, *delta)
+		}
+	}
+
+	abs := func(value int64) int64 {
+		if value < 0 {
+			return -value
+		}
+		return value
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if a, b := abs(functions[i].FlatDelta), abs(functions[j].FlatDelta); a != b {
+			return a > b
+		}
+		if a, b := abs(functions[i].CumDelta), abs(functions[j].CumDelta); a != b {
+			return a > b
+		}
+		return functions[i].Function < functions[j].Function
+	})
+	return functions[:min(limit, len(functions))]
+}
@@ -0,0 +1,388 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/pprof/profile"
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/models"
+)
+
+// Report types of a pprof profile, equivalent to the -top, -list and -tree reports of go tool pprof.
+const (
+	TopReport  = "top"
+	ListReport = "list"
+	TreeReport = "tree"
+)
+
+const DefaultProfileReportLimit = 50 // Number of functions reported by the top and tree reports when no limit is given
+
+var (
+	ErrProfileNotFound   = errors.New("profile not found")   // The profile has not been recorded or its file no longer exists
+	ErrUnknownSampleType = errors.New("unknown sample type") // The profile has no such sample type
+)
+
+// ProfileReportOptions configures the report of a profile.
+type ProfileReportOptions struct {
+	Type       string // TopReport, ListReport or TreeReport
+	Function   string // Function the list report is made of, its closures are included
+	Focus      string // Only the samples with a function starting with the prefix in their stack are reported, ex. "main."
+	SampleType string // Sample type reported, ex. "alloc_space" for a heap profile. Defaults to the default sample type of the profile
+	Limit      int    // Number of functions reported by the top and tree reports, DefaultProfileReportLimit when not set
+}
+
+// frame is a function call in the stack of a sample.
+type frame struct {
+	function string
+	file     string
+	line     int64
+}
+
+// ReadProfile reads and decodes a pprof profile, ErrProfileNotFound is returned when the file does not exist.
+func ReadProfile(path string) (*profile.Profile, error) {
+	if path == "" {
+		return nil, ErrProfileNotFound
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, path)
+	} else if err != nil {
+		return nil, fmt.Errorf("error opening profile %s: %w", path, err)
+	}
+	defer f.Close()
+
+	p, err := profile.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding profile %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// GenerateProfileReport decodes the profile and generates the report, without depending on the Go toolchain.
+func GenerateProfileReport(path string, options ProfileReportOptions) (models.ProfileReport, error) {
+	report := models.ProfileReport{Path: path, Type: options.Type}
+
+	p, err := ReadProfile(path)
+	if err != nil {
+		return report, err
+	}
+
+	index, err := sampleIndex(p, options.SampleType)
+	if err != nil {
+		return report, err
+	}
+	report.SampleType = p.SampleType[index].Type
+	report.Unit = p.SampleType[index].Unit
+
+	limit := options.Limit
+	if limit <= 0 {
+		limit = DefaultProfileReportLimit
+	}
+
+	samples := focusSamples(p.Sample, options.Focus)
+	for _, s := range samples {
+		report.Total += s.Value[index]
+	}
+
+	switch options.Type {
+	case TopReport:
+		report.Top = topReport(samples, index, report.Total, limit)
+	case ListReport:
+		if options.Function == "" {
+			return report, errors.New("a function is required for the list report")
+		}
+		report.List = listReport(samples, index, options.Function)
+	case TreeReport:
+		report.Tree = treeReport(samples, index, report.Total, limit)
+	default:
+		return report, fmt.Errorf("unknown report type %q, expected %s, %s or %s", options.Type, TopReport, ListReport, TreeReport)
+	}
+	return report, nil
+}
+
+// sampleIndex returns the index of the sample type in the sample values, defaulting like go tool pprof to the
+// default sample type of the profile or else to the last sample type.
+func sampleIndex(p *profile.Profile, sampleType string) (int, error) {
+	if len(p.SampleType) == 0 {
+		return 0, errors.New("the profile has no sample types")
+	}
+	if sampleType == "" {
+		sampleType = p.DefaultSampleType
+	}
+	if sampleType == "" {
+		return len(p.SampleType) - 1, nil
+	}
+
+	var types []string
+	for i, t := range p.SampleType {
+		if t.Type == sampleType {
+			return i, nil
+		}
+		types = append(types, t.Type)
+	}
+	return 0, fmt.Errorf("%w %q, the profile has %s", ErrUnknownSampleType, sampleType, strings.Join(types, ", "))
+}
+
+// sampleFrames returns the stack of the sample from the leaf to the root, the functions inlined in a location included.
+func sampleFrames(s *profile.Sample) []frame {
+	var frames []frame
+	for _, location := range s.Location {
+		if len(location.Line) == 0 { // Not symbolized
+			frames = append(frames, frame{function: fmt.Sprintf("0x%x", location.Address)})
+			continue
+		}
+		for _, line := range location.Line { // The last line is the caller the previous ones were inlined into
+			f := frame{line: line.Line}
+			if line.Function != nil {
+				f.function, f.file = line.Function.Name, line.Function.Filename
+			}
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// focusSamples returns the samples with a function starting with the prefix in their stack, or every sample when the prefix is empty.
+func focusSamples(samples []*profile.Sample, prefix string) []*profile.Sample {
+	if prefix == "" {
+		return samples
+	}
+
+	var focused []*profile.Sample
+	for _, s := range samples {
+		for _, f := range sampleFrames(s) {
+			if strings.HasPrefix(f.function, prefix) {
+				focused = append(focused, s)
+				break
+			}
+		}
+	}
+	return focused
+}
+
+// isTracedFunction returns whether the profiled function is the traced function or one of its closures.
+// The name of a function traced by TraceFunction has the slashes of its package path replaced by dashes.
+func isTracedFunction(function, traced string) bool {
+	for _, name := range []string{function, strings.ReplaceAll(function, "/", "-")} {
+		if name == traced || strings.HasPrefix(name, traced+".func") {
+			return true
+		}
+	}
+	return false
+}
+
+// topReport returns the functions with the most samples in their own code, like go tool pprof -top.
+func topReport(samples []*profile.Sample, index int, total int64, limit int) []models.ProfileFunction {
+	functions := functionValues(samples, index)
+	top := make([]models.ProfileFunction, 0, len(functions))
+	for _, function := range functions {
+		function.FlatPercent = percentOf(function.Flat, total)
+		function.CumPercent = percentOf(function.Cum, total)
+		top = append(top, *function)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Flat != top[j].Flat {
+			return top[i].Flat > top[j].Flat
+		}
+		if top[i].Cum != top[j].Cum {
+			return top[i].Cum > top[j].Cum
+		}
+		return top[i].Function < top[j].Function
+	})
+	return top[:min(limit, len(top))]
+}
+
+// functionValues returns the flat and cumulative values of the functions of the samples, by function name.
+func functionValues(samples []*profile.Sample, index int) map[string]*models.ProfileFunction {
+	functions := make(map[string]*models.ProfileFunction)
+	get := func(f frame) *models.ProfileFunction {
+		if _, ok := functions[f.function]; !ok {
+			functions[f.function] = &models.ProfileFunction{Function: f.function, File: f.file}
+		}
+		return functions[f.function]
+	}
+
+	for _, s := range samples {
+		value := s.Value[index]
+		frames := sampleFrames(s)
+		if len(frames) == 0 {
+			continue
+		}
+		get(frames[0]).Flat += value
+
+		seen := make(map[string]bool) // A recursive function is counted once per sample
+		for _, f := range frames {
+			if !seen[f.function] {
+				seen[f.function] = true
+				get(f).Cum += value
+			}
+		}
+	}
+	return functions
+}
+
+// listReport returns the lines of the traced function and its closures with their samples, like go tool pprof -list.
+// The source of the lines is included when the source files are available.
+func listReport(samples []*profile.Sample, index int, traced string) []models.ProfileSource {
+	sources := make(map[string]*models.ProfileSource)
+	lines := make(map[string]map[int64]*models.ProfileSourceLine)
+
+	for _, s := range samples {
+		value := s.Value[index]
+		seenFunctions, seenLines := make(map[string]bool), make(map[frame]bool) // A recursive function is counted once per sample
+		for i, f := range sampleFrames(s) {
+			if !isTracedFunction(f.function, traced) {
+				continue
+			}
+			source, ok := sources[f.function]
+			if !ok {
+				source = &models.ProfileSource{Function: f.function, File: f.file}
+				sources[f.function] = source
+				lines[f.function] = make(map[int64]*models.ProfileSourceLine)
+			}
+			line, ok := lines[f.function][f.line]
+			if !ok {
+				line = &models.ProfileSourceLine{Line: f.line}
+				lines[f.function][f.line] = line
+			}
+
+			if i == 0 {
+				source.Flat += value
+				line.Flat += value
+			}
+			if !seenFunctions[f.function] {
+				seenFunctions[f.function] = true
+				source.Cum += value
+			}
+			if !seenLines[f] {
+				seenLines[f] = true
+				line.Cum += value
+			}
+		}
+	}
+
+	list := make([]models.ProfileSource, 0, len(sources))
+	for name, source := range sources {
+		code := readSourceLines(source.File)
+		for _, line := range lines[name] {
+			if line.Line > 0 && int(line.Line) <= len(code) {
+				line.Source = code[line.Line-1]
+			}
+			source.Lines = append(source.Lines, *line)
+		}
+		sort.Slice(source.Lines, func(i, j int) bool { return source.Lines[i].Line < source.Lines[j].Line })
+		list = append(list, *source)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Function < list[j].Function })
+	return list
+}
+
+// readSourceLines returns the lines of the source file, or nil when it is not available, ex. in a container without the sources.
+func readSourceLines(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+// treeReport returns the functions with the most samples in their call tree along with their callers and callees, like go tool pprof -tree.
+func treeReport(samples []*profile.Sample, index int, total int64, limit int) []models.ProfileTreeNode {
+	type node struct {
+		function         models.ProfileFunction
+		callers, callees map[string]int64
+	}
+	nodes := make(map[string]*node)
+	get := func(f frame) *node {
+		if _, ok := nodes[f.function]; !ok {
+			nodes[f.function] = &node{
+				function: models.ProfileFunction{Function: f.function, File: f.file},
+				callers:  make(map[string]int64),
+				callees:  make(map[string]int64),
+			}
+		}
+		return nodes[f.function]
+	}
+
+	for _, s := range samples {
+		value := s.Value[index]
+		frames := sampleFrames(s)
+		if len(frames) == 0 {
+			continue
+		}
+		get(frames[0]).function.Flat += value
+
+		seen := make(map[string]bool)
+		edges := make(map[[2]string]bool) // A call edge of a recursive function is counted once per sample
+		for i, f := range frames {
+			n := get(f)
+			if !seen[f.function] {
+				seen[f.function] = true
+				n.function.Cum += value
+			}
+			if i+1 < len(frames) {
+				caller := frames[i+1].function
+				if edge := [2]string{caller, f.function}; !edges[edge] {
+					edges[edge] = true
+					n.callers[caller] += value
+					get(frames[i+1]).callees[f.function] += value
+				}
+			}
+		}
+	}
+
+	tree := make([]models.ProfileTreeNode, 0, len(nodes))
+	for _, n := range nodes {
+		n.function.FlatPercent = percentOf(n.function.Flat, total)
+		n.function.CumPercent = percentOf(n.function.Cum, total)
+		tree = append(tree, models.ProfileTreeNode{
+			ProfileFunction: n.function,
+			Callers:         profileEdges(n.callers),
+			Callees:         profileEdges(n.callees),
+		})
+	}
+	sort.Slice(tree, func(i, j int) bool {
+		if tree[i].Cum != tree[j].Cum {
+			return tree[i].Cum > tree[j].Cum
+		}
+		return tree[i].Function < tree[j].Function
+	})
+	return tree[:min(limit, len(tree))]
+}
+
+// profileEdges returns the calls between functions sorted by value.
+func profileEdges(values map[string]int64) []models.ProfileEdge {
+	edges := make([]models.ProfileEdge, 0, len(values))
+	for function, value := range values {
+		edges = append(edges, models.ProfileEdge{Function: function, Value: value})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Value != edges[j].Value {
+			return edges[i].Value > edges[j].Value
+		}
+		return edges[i].Function < edges[j].Function
+	})
+	return edges
+}
+
+// percentOf returns the share of the total as a percentage.
+func percentOf(value, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return common.RoundFloat64(float64(value)/float64(total)*100, 2)
+}
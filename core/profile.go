@@ -10,17 +10,25 @@ import (
 )
 
 // StartCPUProfile starts the CPU profile and writes it to the specified file.
+// Only one CPU profile can run in a process, the file is removed when the profile could not be started.
 func StartCPUProfile(filename string) (*os.File, error) {
 	f, err := os.Create(filename)
 	if err != nil {
 		return nil, err
 	}
-	pprof.StartCPUProfile(f)
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		os.Remove(filename)
+		return nil, err
+	}
 	return f, nil
 }
 
-// StopCPUProfile stops the current CPU profile and writes it to the specified file.
+// StopCPUProfile stops the current CPU profile and closes the file it was written to.
 func StopCPUProfile(f *os.File) {
+	if f == nil {
+		return
+	}
 	pprof.StopCPUProfile()
 	f.Close()
 }
@@ -31,25 +39,16 @@ func WriteHeapProfile(filename string) error {
 	if err != nil {
 		return err
 	}
+	defer f.Close()
+
 	runtime.GC() // Get up-to-date statistics
 	return pprof.WriteHeapProfile(f)
 }
 
-// CollectGoRoutinesInfo returns the number of running Go routines and their stack traces split into separate goroutine blocks.
+// CollectGoRoutinesInfo returns the number of running Go routines, their stack traces split into separate goroutine blocks
+// and the goroutines grouped by identical stacks.
 func CollectGoRoutinesInfo() models.GoRoutinesStatistic {
-	// Creating a buffer to hold the stack trace
-	stackBuffer := make([]byte, 1<<20)
-	stackSize := runtime.Stack(stackBuffer, true)
-
-	stackTrace := string(stackBuffer[:stackSize]) // converting the stack trace to a single string
-
-	goroutineBlocks := SplitGoroutines(stackTrace)           // splitting the stack trace into separate goroutine blocks
-	totalNumberOfRunningGoRoutines := runtime.NumGoroutine() // getting the total number of running goroutines
-
-	return models.GoRoutinesStatistic{
-		NumberOfGoroutines: totalNumberOfRunningGoRoutines,
-		StackView:          goroutineBlocks,
-	}
+	return AnalyzeGoroutines(GoroutineFilter{}, true)
 }
 
 // SplitGoroutines splits the input stack trace into separate goroutine blocks based on new lines and "goroutine" identifiers.
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const (
+	schedLatenciesMetric = "/sched/latencies:seconds"
+	gcPausesMetric       = "/gc/pauses:seconds"
+	heapGoalMetric       = "/gc/heap/goal:bytes"
+	heapLiveMetric       = "/gc/heap/live:bytes"
+	memoryLimitMetric    = "/gc/gomemlimit:bytes"
+	gcCyclesMetric       = "/gc/cycles/total:gc-cycles"
+	mutexWaitMetric      = "/sync/mutex/wait/total:seconds"
+	heapAllocsMetric     = "/gc/heap/allocs:bytes"
+)
+
+// RuntimeLatencyBuckets are the upper bounds in seconds the runtime latency histograms are reported with.
+var RuntimeLatencyBuckets = []float64{1e-6, 1e-5, 5e-5, 1e-4, 5e-4, 1e-3, 5e-3, 1e-2, 5e-2, 0.1, 0.5, 1}
+
+var (
+	runtimeSamples        = newRuntimeSamples()                        // Reused between collections, guarded by collectMu
+	lastRuntimeHistograms = make(map[string]*metrics.Float64Histogram) // Histograms read by the previous collection, guarded by collectMu
+)
+
+// newRuntimeSamples returns the samples of the runtime metrics supported by the Go version the service is built with.
+func newRuntimeSamples() []metrics.Sample {
+	supported := make(map[string]bool)
+	for _, description := range metrics.All() {
+		supported[description.Name] = true
+	}
+
+	var samples []metrics.Sample
+	for _, name := range []string{schedLatenciesMetric, gcPausesMetric, heapGoalMetric, heapLiveMetric, memoryLimitMetric, gcCyclesMetric, mutexWaitMetric} {
+		if supported[name] {
+			samples = append(samples, metrics.Sample{Name: name})
+		}
+	}
+	return samples
+}
+
+// GetRuntimeStatistics reads the GC, scheduler and heap statistics from runtime/metrics, which unlike runtime.ReadMemStats does not stop the world.
+// The percentiles of the histograms are measured since the previous collection. It must be called with collectMu held.
+func GetRuntimeStatistics() models.RuntimeStatistics {
+	metrics.Read(runtimeSamples)
+
+	var stats models.RuntimeStatistics
+	for _, sample := range runtimeSamples {
+		switch sample.Name {
+		case schedLatenciesMetric:
+			stats.SchedulerLatency = runtimeHistogram(sample)
+		case gcPausesMetric:
+			stats.GCPauses = runtimeHistogram(sample)
+		case heapGoalMetric:
+			stats.HeapGoalBytes = sample.Value.Uint64()
+		case heapLiveMetric:
+			stats.HeapLiveBytes = sample.Value.Uint64()
+		case memoryLimitMetric:
+			if limit := sample.Value.Uint64(); limit != math.MaxInt64 { // math.MaxInt64 is the default, no limit
+				stats.MemoryLimitBytes = limit
+			}
+		case gcCyclesMetric:
+			stats.GCCycles = sample.Value.Uint64()
+		case mutexWaitMetric:
+			stats.MutexWaitMs = common.RoundFloat64(sample.Value.Float64()*1e3, 3)
+		}
+	}
+	return stats
+}
+
+// runtimeHistogram converts a runtime histogram into the reported buckets, and estimates the percentiles
+// from the observations since the previous collection at the full resolution of the runtime histogram.
+func runtimeHistogram(sample metrics.Sample) models.RuntimeHistogram {
+	if sample.Value.Kind() != metrics.KindFloat64Histogram {
+		return models.RuntimeHistogram{}
+	}
+	current := sample.Value.Float64Histogram()
+
+	var histogram models.RuntimeHistogram
+	for _, count := range current.Counts {
+		histogram.Count += count
+	}
+
+	// Cumulative counts of the reported buckets, a runtime bucket is counted once it is below the upper bound
+	var cumulative uint64
+	next := 0
+	for _, upperBound := range RuntimeLatencyBuckets {
+		for next < len(current.Counts) && current.Buckets[next+1] <= upperBound {
+			cumulative += current.Counts[next]
+			next++
+		}
+		histogram.Buckets = append(histogram.Buckets, models.HistogramBucket{UpperBound: upperBound, Count: cumulative})
+	}
+
+	// Observations since the previous collection, the histograms keep the same buckets for the lifetime of the process
+	counts := append([]uint64{}, current.Counts...)
+	if previous, ok := lastRuntimeHistograms[sample.Name]; ok && len(previous.Counts) == len(counts) {
+		for i := range counts {
+			counts[i] -= min(counts[i], previous.Counts[i])
+		}
+	}
+	lastRuntimeHistograms[sample.Name] = &metrics.Float64Histogram{Counts: append([]uint64{}, current.Counts...), Buckets: current.Buckets}
+
+	var windowBuckets []models.HistogramBucket
+	var windowCount uint64
+	for i, count := range counts {
+		windowCount += count
+		upperBound := current.Buckets[i+1]
+		if math.IsInf(upperBound, 1) {
+			upperBound = current.Buckets[i] // Observations above the highest bound are estimated as the bound
+		}
+		windowBuckets = append(windowBuckets, models.HistogramBucket{UpperBound: upperBound, Count: windowCount})
+	}
+
+	histogram.P50Ms = common.RoundFloat64(bucketQuantile(0.5, windowBuckets, windowCount)*1e3, 4)
+	histogram.P90Ms = common.RoundFloat64(bucketQuantile(0.9, windowBuckets, windowCount)*1e3, 4)
+	histogram.P99Ms = common.RoundFloat64(bucketQuantile(0.99, windowBuckets, windowCount)*1e3, 4)
+	return histogram
+}
+
+// heapAllocatedBytes returns the bytes allocated on the heap since the process started, read from runtime/metrics
+// without stopping the world. The small objects are counted by span, as the runtime refills the cache of a processor.
+func heapAllocatedBytes() uint64 {
+	sample := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0 // Unsupported by the Go version the service is built with
+	}
+	return sample[0].Value.Uint64()
+}
+
+// memStatsMetrics are the runtime metrics the memory statistics are read from
+var memStatsMetrics = []string{
+	"/memory/classes/total:bytes",
+	"/memory/classes/heap/objects:bytes",
+	"/memory/classes/heap/unused:bytes",
+	"/memory/classes/heap/free:bytes",
+	"/memory/classes/heap/released:bytes",
+	"/memory/classes/heap/stacks:bytes",
+	"/memory/classes/os-stacks:bytes",
+	"/memory/classes/metadata/mspan/inuse:bytes",
+	"/memory/classes/metadata/mspan/free:bytes",
+	"/memory/classes/metadata/mcache/inuse:bytes",
+	"/memory/classes/metadata/mcache/free:bytes",
+	"/memory/classes/metadata/other:bytes",
+	"/memory/classes/profiling/buckets:bytes",
+	"/memory/classes/other:bytes",
+	heapAllocsMetric,
+	"/gc/heap/allocs:objects",
+	"/gc/heap/frees:objects",
+	"/gc/heap/objects:objects",
+	heapGoalMetric,
+	gcCyclesMetric,
+	"/gc/cycles/forced:gc-cycles",
+	"/cpu/classes/gc/total:cpu-seconds",
+	"/cpu/classes/total:cpu-seconds",
+}
+
+// ReadMemStats returns the memory statistics read from runtime/metrics and the GC statistics of runtime/debug,
+// which unlike runtime.ReadMemStats do not stop the world. The fields are derived the way the runtime derives them,
+// except Mallocs not counting the tiny allocations, Lookups always being 0 and GCCPUFraction being measured from the CPU time of the GC.
+func ReadMemStats() *runtime.MemStats {
+	samples := make([]metrics.Sample, len(memStatsMetrics))
+	for i, name := range memStatsMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	values := make(map[string]float64, len(samples))
+	for _, sample := range samples {
+		switch sample.Value.Kind() {
+		case metrics.KindUint64:
+			values[sample.Name] = float64(sample.Value.Uint64())
+		case metrics.KindFloat64:
+			values[sample.Name] = sample.Value.Float64()
+		} // Metrics unsupported by the Go version the service is built with are left at 0
+	}
+	value := func(name string) uint64 { return uint64(values[name]) }
+
+	var gcStats debug.GCStats
+	debug.ReadGCStats(&gcStats)
+
+	m := &runtime.MemStats{
+		Sys:          value("/memory/classes/total:bytes"),
+		Alloc:        value("/memory/classes/heap/objects:bytes"),
+		TotalAlloc:   value(heapAllocsMetric),
+		Mallocs:      value("/gc/heap/allocs:objects"),
+		Frees:        value("/gc/heap/frees:objects"),
+		HeapAlloc:    value("/memory/classes/heap/objects:bytes"),
+		HeapInuse:    value("/memory/classes/heap/objects:bytes") + value("/memory/classes/heap/unused:bytes"),
+		HeapIdle:     value("/memory/classes/heap/free:bytes") + value("/memory/classes/heap/released:bytes"),
+		HeapReleased: value("/memory/classes/heap/released:bytes"),
+		HeapObjects:  value("/gc/heap/objects:objects"),
+		StackInuse:   value("/memory/classes/heap/stacks:bytes"),
+		StackSys:     value("/memory/classes/heap/stacks:bytes") + value("/memory/classes/os-stacks:bytes"),
+		MSpanInuse:   value("/memory/classes/metadata/mspan/inuse:bytes"),
+		MSpanSys:     value("/memory/classes/metadata/mspan/inuse:bytes") + value("/memory/classes/metadata/mspan/free:bytes"),
+		MCacheInuse:  value("/memory/classes/metadata/mcache/inuse:bytes"),
+		MCacheSys:    value("/memory/classes/metadata/mcache/inuse:bytes") + value("/memory/classes/metadata/mcache/free:bytes"),
+		BuckHashSys:  value("/memory/classes/profiling/buckets:bytes"),
+		GCSys:        value("/memory/classes/metadata/other:bytes"),
+		OtherSys:     value("/memory/classes/other:bytes"),
+		NextGC:       value(heapGoalMetric),
+		PauseTotalNs: uint64(gcStats.PauseTotal),
+		NumGC:        uint32(value(gcCyclesMetric)),
+		NumForcedGC:  uint32(value("/gc/cycles/forced:gc-cycles")),
+	}
+	m.HeapSys = m.HeapInuse + m.HeapIdle
+	if !gcStats.LastGC.IsZero() {
+		m.LastGC = uint64(gcStats.LastGC.UnixNano())
+	}
+	if total := values["/cpu/classes/total:cpu-seconds"]; total > 0 {
+		m.GCCPUFraction = values["/cpu/classes/gc/total:cpu-seconds"] / total
+	}
+	return m
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestReadMemStatsMatchesRuntime(t *testing.T) {
+	runtime.GC()
+	var want runtime.MemStats
+	runtime.ReadMemStats(&want)
+	got := ReadMemStats()
+
+	if got.NumGC != want.NumGC {
+		t.Errorf("NumGC = %d, want %d", got.NumGC, want.NumGC)
+	}
+	if got.NumForcedGC != want.NumForcedGC {
+		t.Errorf("NumForcedGC = %d, want %d", got.NumForcedGC, want.NumForcedGC)
+	}
+	if got.PauseTotalNs != want.PauseTotalNs {
+		t.Errorf("PauseTotalNs = %d, want %d", got.PauseTotalNs, want.PauseTotalNs)
+	}
+	if got.LastGC != want.LastGC {
+		t.Errorf("LastGC = %d, want %d", got.LastGC, want.LastGC)
+	}
+	if got.TotalAlloc < want.TotalAlloc {
+		t.Errorf("TotalAlloc = %d, want at least %d", got.TotalAlloc, want.TotalAlloc)
+	}
+
+	// The other statistics move with the allocations between the two reads
+	for _, tt := range []struct {
+		name      string
+		got, want uint64
+	}{
+		{"Sys", got.Sys, want.Sys},
+		{"HeapAlloc", got.HeapAlloc, want.HeapAlloc},
+		{"HeapSys", got.HeapSys, want.HeapSys},
+		{"HeapInuse", got.HeapInuse, want.HeapInuse},
+		{"StackSys", got.StackSys, want.StackSys},
+		{"NextGC", got.NextGC, want.NextGC},
+	} {
+		if diff := float64(tt.got) - float64(tt.want); diff > 0.25*float64(tt.want) || -diff > 0.25*float64(tt.want) {
+			t.Errorf("%s = %d, want about %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+var allocationSink []byte
+
+func TestHeapAllocatedBytes(t *testing.T) {
+	before := heapAllocatedBytes()
+	allocationSink = make([]byte, 1<<20) // Large objects are counted as they are allocated
+	if got := heapAllocatedBytes() - before; got < 1<<20 {
+		t.Errorf("allocated = %d bytes, want at least %d", got, 1<<20)
+	}
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/models"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/host"
+)
+
+var (
+	samplerMu     sync.Mutex           // Guards the snapshot and the sampler goroutine
+	snapshot      *models.ServiceStats // Latest statistics collected by the sampler
+	samplerCancel context.CancelFunc   // Cancel function for the sampler goroutine
+	samplerWg     sync.WaitGroup       // Waits for the sampler goroutine to stop
+
+	collectMu       sync.Mutex               // Serializes the collections, the CPU usage is measured between two of them
+	lastCPUTimes    cpuTimes                 // CPU times read by the previous collection
+	lastCPUUsage    cpuUsage                 // CPU usage measured by the last collection
+	samplerInterval time.Duration            // Time between two collections of the sampler, 0 when it is not running
+	samplerStats    models.SamplerStatistics // Overhead of the collections
+)
+
+// cpuTimes holds the CPU time counters read at a point in time.
+type cpuTimes struct {
+	at      time.Time
+	service float64 // Seconds of CPU used by the service
+	busy    float64 // Seconds of CPU used by the system
+	total   float64 // Seconds of CPU available to the system
+}
+
+// cpuUsage holds the CPU usage between two collections.
+type cpuUsage struct {
+	servicePercent float64 // Percentage of one core used by the service, like process.CPUPercent
+	systemPercent  float64 // Percentage of every core used by the system, like cpu.Percent
+}
+
+// StartSampler collects the service statistics every interval in the background, GetServiceStats then serves
+// the latest snapshot instead of collecting the statistics on every call. The first snapshot is collected before returning.
+func StartSampler(interval time.Duration) {
+	StopSampler() // Stopping the previous sampler, if any
+
+	samplerMu.Lock()
+	defer samplerMu.Unlock()
+
+	samplerInterval = interval
+	stats := CollectServiceStats()
+	snapshot = &stats
+
+	var ctx context.Context
+	ctx, samplerCancel = context.WithCancel(context.Background())
+
+	ticker := time.NewTicker(interval)
+	samplerWg.Add(1)
+	go func() {
+		defer samplerWg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stats := CollectServiceStats()
+				samplerMu.Lock()
+				snapshot = &stats
+				samplerMu.Unlock()
+			}
+		}
+	}()
+}
+
+// StopSampler stops the sampler goroutine and waits for it to return, GetServiceStats then collects the statistics on every call.
+func StopSampler() {
+	samplerMu.Lock()
+	if samplerCancel != nil {
+		samplerCancel()
+		samplerCancel = nil
+	}
+	snapshot = nil
+	samplerMu.Unlock()
+
+	samplerWg.Wait()
+
+	collectMu.Lock()
+	samplerInterval = 0
+	collectMu.Unlock()
+}
+
+// GetServiceStats returns the latest statistics collected by the sampler, or collects them when the sampler is not running.
+func GetServiceStats() models.ServiceStats {
+	samplerMu.Lock()
+	if snapshot != nil {
+		stats := *snapshot
+		samplerMu.Unlock()
+		return stats
+	}
+	samplerMu.Unlock()
+
+	return CollectServiceStats()
+}
+
+// sampleCPU measures the CPU usage of the service and the system since the previous collection.
+// The first collection measures the usage since the service and the system started.
+// The usage of the previous collection is kept when the CPU times cannot be read.
+func sampleCPU() error {
+	current, err := readCPUTimes()
+	if err != nil {
+		return err
+	}
+
+	previous := lastCPUTimes
+	if previous.at.IsZero() {
+		previous = cpuTimes{at: processStartTime()} // The system counters start at zero on boot
+	}
+
+	var usage cpuUsage
+	if elapsed := current.at.Sub(previous.at).Seconds(); elapsed > 0 {
+		usage.servicePercent = (current.service - previous.service) / elapsed * 100
+	}
+	if total := current.total - previous.total; total > 0 {
+		usage.systemPercent = (current.busy - previous.busy) / total * 100
+	}
+
+	lastCPUTimes = current
+	lastCPUUsage = usage
+	return nil
+}
+
+// readCPUTimes reads the CPU time counters of the service and the system.
+func readCPUTimes() (cpuTimes, error) {
+	now := time.Now()
+
+	proc, err := common.GetProcessObject()
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	procTimes, err := proc.Times()
+	if err != nil {
+		return cpuTimes{}, fmt.Errorf("error fetching CPU times of the service: %w", err)
+	}
+
+	sysTimes, err := cpu.Times(false)
+	if err != nil {
+		return cpuTimes{}, fmt.Errorf("error fetching CPU times of the system: %w", err)
+	}
+
+	var busy, total float64
+	for _, t := range sysTimes {
+		total += t.Total()
+		busy += t.Total() - t.Idle
+	}
+
+	return cpuTimes{at: now, service: procTimes.User + procTimes.System, busy: busy, total: total}, nil
+}
+
+// processStartTime returns the time the service process started, or the boot time when it is not available.
+func processStartTime() time.Time {
+	if proc, err := common.GetProcessObject(); err == nil {
+		if createTime, err := proc.CreateTime(); err == nil {
+			return time.UnixMilli(createTime)
+		}
+	}
+
+	bootTime, _ := host.BootTime()
+	return time.Unix(int64(bootTime), 0)
+}
+
+// recordCollection updates the overhead statistics with the duration of a collection.
+func recordCollection(sampledAt time.Time, duration time.Duration) models.SamplerStatistics {
+	durationMs := durationToMs(duration)
+
+	samplerStats.SampledAt = sampledAt
+	samplerStats.IntervalMs = durationToMs(samplerInterval)
+	samplerStats.CollectionCount++
+	samplerStats.LastCollectionMs = durationMs
+	samplerStats.TotalCollectionMs += durationMs
+	samplerStats.AvgCollectionMs = samplerStats.TotalCollectionMs / float64(samplerStats.CollectionCount)
+	if durationMs > samplerStats.MaxCollectionMs {
+		samplerStats.MaxCollectionMs = durationMs
+	}
+
+	samplerStats.OverheadPercent = 0
+	if samplerInterval > 0 {
+		samplerStats.OverheadPercent = common.RoundFloat64(float64(duration)/float64(samplerInterval)*100, 3)
+	}
+	return samplerStats
+}
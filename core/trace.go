@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/iyashjayesh/monigo/models"
+)
+
+type spanKey struct{}
+
+// span is a traced call, the span of a call traced within another one is linked to it through the context.
+type span struct {
+	name   string
+	parent *span
+}
+
+// TraceFunctionCtx traces the function under the given name, recording its execution time and error. The context passed
+// to the function carries the span of the call, so the functions it traces are recorded as its children.
+// The call is neither profiled nor stops the world to read the memory statistics, so it can be left on in production.
+// A panic of the function is recorded and propagated to the caller.
+func TraceFunctionCtx(ctx context.Context, name string, fn func(ctx context.Context) error) (err error) {
+	current := &span{name: name}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		current.parent = parent
+	}
+
+	initialGoroutines := runtime.NumGoroutine()
+	start := time.Now()
+	panicked := true // Cleared once the function returns, the panic is recorded without being recovered
+	defer func() {
+		call := functionCall{
+			details: models.FunctionMetrics{
+				FunctionLastRanAt: start,
+				GoroutineCount:    max(runtime.NumGoroutine()-initialGoroutines, 0),
+				ExecutionTime:     time.Since(start),
+			},
+			err:      err,
+			panicked: panicked,
+		}
+		if current.parent != nil {
+			call.parent = current.parent.name
+		}
+		recordFunctionCall(name, call)
+	}()
+
+	err = fn(context.WithValue(ctx, spanKey{}, current))
+	panicked = false
+	return err
+}
+
+// Trace traces the function under the given name like TraceFunctionCtx, returning the result of the function.
+func Trace[T any](ctx context.Context, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := TraceFunctionCtx(ctx, name, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
@@ -3,15 +3,19 @@ module github.com/iyashjayesh/monigo
 go 1.21.0
 
 require (
+	github.com/google/pprof v0.0.0-20240727154555-813a5fbdbec8
 	github.com/nakabonne/tstorage v0.3.6
+	github.com/prometheus/client_model v0.6.1
+	github.com/prometheus/common v0.63.0
 	github.com/shirou/gopsutil v3.21.11+incompatible
 )
 
 require (
 	github.com/go-ole/go-ole v1.3.0 // indirect
-	github.com/stretchr/testify v1.9.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
 	github.com/tklauser/go-sysconf v0.3.14 // indirect
 	github.com/tklauser/numcpus v0.8.0 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
-	golang.org/x/sys v0.25.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
 )
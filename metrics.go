@@ -0,0 +1,39 @@
+package monigo
+
+import "github.com/iyashjayesh/monigo/core"
+
+// Labels are the name and value pairs identifying a custom metric, ex. {"queue": "orders"}.
+// A label named like a label of monigo, ex. "host" or "le", is renamed "exported_<label>"
+type Labels = map[string]string
+
+// CustomMetricPrefix prefixes the names of the custom metrics, ex. "custom_orders_processed"
+const CustomMetricPrefix = core.CustomMetricPrefix
+
+// Counter is a custom metric that only goes up, ex. orders processed
+type Counter = core.Counter
+
+// Gauge is a custom metric that can go up and down, ex. queue depth
+type Gauge = core.Gauge
+
+// Histogram is a custom metric counting observations in buckets, ex. order value
+type Histogram = core.Histogram
+
+// DefaultBuckets are the bucket upper bounds used by the histograms created without buckets
+var DefaultBuckets = core.DefaultBuckets
+
+// NewCounter registers a counter recorded with the service metrics, the existing counter is returned
+// if one is already registered with the same name and labels
+func NewCounter(name, description string, labels Labels) *Counter {
+	return core.NewCounter(name, description, labels)
+}
+
+// NewGauge registers a gauge recorded with the service metrics, the existing gauge is returned
+// if one is already registered with the same name and labels
+func NewGauge(name, description string, labels Labels) *Gauge {
+	return core.NewGauge(name, description, labels)
+}
+
+// NewHistogram registers a histogram with the bucket upper bounds, DefaultBuckets are used when buckets is nil
+func NewHistogram(name, description string, buckets []float64, labels Labels) *Histogram {
+	return core.NewHistogram(name, description, buckets, labels)
+}
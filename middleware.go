@@ -0,0 +1,89 @@
+package monigo
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/iyashjayesh/monigo/core"
+)
+
+// statusRecorder wraps the http.ResponseWriter to capture the status code of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+// WriteHeader captures the status code and writes it to the underlying writer
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the data to the underlying writer, defaulting the status code to 200
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.statusCode = http.StatusOK
+		r.wroteHeader = true
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer if it supports flushing
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, so http.ResponseController reaches its Hijack, deadlines and flushing
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Hijack hijacks the connection of the underlying writer, ex. for a websocket upgrade
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if !r.wroteHeader {
+		r.statusCode = http.StatusSwitchingProtocols
+		r.wroteHeader = true
+	}
+	return hijacker.Hijack()
+}
+
+// Middleware records the request count, latency and status code of every request served by the handler.
+// Requests are grouped by the route pattern when the handler is a *http.ServeMux, the requests matching
+// no pattern and those of any other handler being grouped under "unmatched". Use RouteMiddleware to name
+// the routes of another router.
+func Middleware(next http.Handler) http.Handler {
+	return RouteMiddleware(next, func(r *http.Request) string {
+		if mux, ok := next.(*http.ServeMux); ok {
+			if _, pattern := mux.Handler(r); pattern != "" {
+				return pattern
+			}
+		}
+		return ""
+	})
+}
+
+// RouteMiddleware records the request count, latency and status code of every request served by the handler,
+// grouped by the route returned by route, ex. the route template of the router. An empty route groups the request
+// under "unmatched". The route must not be the raw URL path, every distinct path would be tracked as a route.
+func RouteMiddleware(next http.Handler, route func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routeName := route(r)
+
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r)
+		core.RecordRequest(routeName, r.Method, recorder.statusCode, time.Since(start))
+	})
+}
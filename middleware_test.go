@@ -0,0 +1,153 @@
+package monigo
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/iyashjayesh/monigo/core"
+	"github.com/iyashjayesh/monigo/models"
+)
+
+// requestStatistics returns the statistics recorded for the route and method, the zero value when there are none.
+func requestStatistics(route, method string) models.RouteStatistics {
+	for _, stats := range core.GetRequestStatistics() {
+		if stats.Route == route && stats.Method == method {
+			return stats
+		}
+	}
+	return models.RouteStatistics{}
+}
+
+func TestMiddlewareGroupsByMuxPattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mux-users/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mux-users/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	handler := Middleware(mux)
+
+	unmatchedBefore := requestStatistics(core.UnmatchedRoute, http.MethodGet).StatusCodes["4xx"]
+	for _, path := range []string{"/mux-users/1", "/mux-users/2", "/mux-users/missing", "/mux-unknown"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	users := requestStatistics("/mux-users/", http.MethodGet)
+	if users.RequestCount != 3 {
+		t.Errorf("/mux-users/ request count = %d, want 3", users.RequestCount)
+	}
+	if users.StatusCodes["2xx"] != 2 || users.StatusCodes["4xx"] != 1 {
+		t.Errorf("/mux-users/ status codes = %v, want 2 2xx and 1 4xx", users.StatusCodes)
+	}
+	if got := requestStatistics(core.UnmatchedRoute, http.MethodGet).StatusCodes["4xx"] - unmatchedBefore; got != 1 {
+		t.Errorf("unmatched 4xx = %d, want 1", got)
+	}
+	if got := requestStatistics("/mux-unknown", http.MethodGet).RequestCount; got != 0 {
+		t.Errorf("the raw path of an unmatched request was tracked as a route")
+	}
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	handler := RouteMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.WriteHeader(http.StatusInternalServerError) // Superfluous, the first status code is recorded
+	}), func(r *http.Request) string {
+		return "/route-orders/{id}"
+	})
+
+	for _, method := range []string{http.MethodPost, http.MethodPost, "PURGE"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/route-orders/42", nil))
+	}
+
+	post := requestStatistics("/route-orders/{id}", http.MethodPost)
+	if post.RequestCount != 2 || post.StatusCodes["2xx"] != 2 {
+		t.Errorf("POST statistics = %+v, want 2 requests with 2xx", post)
+	}
+	if got := requestStatistics("/route-orders/{id}", core.OtherMethod).RequestCount; got != 1 {
+		t.Errorf("OTHER request count = %d, want 1", got)
+	}
+}
+
+func TestMiddlewareFlush(t *testing.T) {
+	handler := RouteMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("chunk"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+	}), func(r *http.Request) string { return "/flush" })
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/flush", nil))
+	if !w.Flushed {
+		t.Error("the response was not flushed")
+	}
+}
+
+func TestMiddlewareUnwrap(t *testing.T) {
+	server := httptest.NewServer(RouteMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only reachable through Unwrap, the recorder does not implement the deadlines
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+			t.Errorf("SetWriteDeadline() error = %v", err)
+		}
+	}), func(r *http.Request) string { return "/unwrap" }))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestMiddlewareHijack(t *testing.T) {
+	server := httptest.NewServer(RouteMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		rw.Flush()
+	}), func(r *http.Request) string { return "/hijack" }))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+
+	// The request is recorded once the handler returns, after the client read the response
+	deadline := time.Now().Add(5 * time.Second)
+	for requestStatistics("/hijack", http.MethodGet).RequestCount == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := requestStatistics("/hijack", http.MethodGet).StatusCodes["1xx"]; got != 1 {
+		t.Errorf("1xx count = %d, want 1", got)
+	}
+}
+
+func TestMiddlewareHijackNotSupported(t *testing.T) {
+	handler := RouteMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if hijacker, ok := w.(http.Hijacker); ok {
+			_, _, err = hijacker.Hijack()
+		}
+		if !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("Hijack() error = %v, want %v", err, http.ErrNotSupported)
+		}
+	}), func(r *http.Request) string { return "/hijack-unsupported" })
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/hijack-unsupported", nil))
+}
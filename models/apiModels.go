@@ -6,21 +6,25 @@ import (
 
 // ServiceStats represents the final statistics of the service.
 type ServiceStats struct {
-	CoreStatistics   CoreStatistics   `json:"core_statistics"`   // Core Statistics
-	LoadStatistics   LoadStatistics   `json:"load_statistics"`   // Load Statistics
-	CPUStatistics    CPUStatistics    `json:"cpu_statistics"`    // CPU Statistics
-	MemoryStatistics MemoryStatistics `json:"memory_statistics"` // Memory Statistics
+	CoreStatistics    CoreStatistics      `json:"core_statistics"`    // Core Statistics
+	LoadStatistics    LoadStatistics      `json:"load_statistics"`    // Load Statistics
+	CPUStatistics     CPUStatistics       `json:"cpu_statistics"`     // CPU Statistics
+	MemoryStatistics  MemoryStatistics    `json:"memory_statistics"`  // Memory Statistics
+	RequestStatistics []RouteStatistics   `json:"request_statistics"` // Request Statistics per route
+	CustomMetrics     []CustomMetric      `json:"custom_metrics"`     // Metrics recorded by the service
+	SamplerStatistics SamplerStatistics   `json:"sampler_statistics"` // Overhead of collecting the statistics
+	Collectors        []CollectorStatus   `json:"collectors"`         // Status of the collectors, the statistics of an unavailable collector are left empty
+	Container         ContainerStatistics `json:"container"`          // cgroup limits and usage of the service
+	Functions         []FunctionMetrics   `json:"functions"`          // Statistics of the traced functions
+	Runtime           RuntimeStatistics   `json:"runtime"`            // GC, scheduler and heap statistics from runtime/metrics
+	DiskIO            DiskIOStatistics    `json:"disk_io"`            // Disk I/O of the service and the devices, and usage of the data volume
 
 	// Additional Metrics
-	HeapAllocByService  string `json:"heap_alloc_by_service"`
-	HeapAllocBySystem   string `json:"heap_alloc_by_system"`
-	TotalAllocByService string `json:"total_alloc_by_service"`
-	TotalMemoryByOS     string `json:"total_memory_by_os"`
-	// DiskIO            float64 `json:"disk_io"` @TODO: Need to work on this
-	NetworkIO struct {
-		BytesSent     float64 `json:"bytes_sent"`
-		BytesReceived float64 `json:"bytes_received"`
-	} `json:"network_io"`
+	HeapAllocByService  string              `json:"heap_alloc_by_service"`
+	HeapAllocBySystem   string              `json:"heap_alloc_by_system"`
+	TotalAllocByService string              `json:"total_alloc_by_service"`
+	TotalMemoryByOS     string              `json:"total_memory_by_os"`
+	NetworkIO           NetworkIOStatistics `json:"network_io"`
 
 	// Health
 	Health ServiceHealth `json:"health"`
@@ -28,10 +32,44 @@ type ServiceStats struct {
 
 // CoreStatistics represents the core statistics of the service.
 type CoreStatistics struct {
-	Goroutines int    `json:"goroutines"`
-	Uptime     string `json:"uptime"`
-	// RequestCount               int64         `json:"request_count"`
-	// TotalDurationTookByRequest time.Duration `json:"total_duration_took_by_request"`
+	Goroutines                   int     `json:"goroutines"`
+	Uptime                       string  `json:"uptime"`
+	RequestCount                 int64   `json:"request_count"`
+	TotalDurationTookByRequestMs float64 `json:"total_duration_took_by_request_ms"` // Total time spent serving the requests in milliseconds
+}
+
+// RouteStatistics represents the request statistics of a single route.
+type RouteStatistics struct {
+	Route           string           `json:"route"`
+	Method          string           `json:"method"`
+	RequestCount    int64            `json:"request_count"`
+	StatusCodes     map[string]int64 `json:"status_codes"` // Request count per status class ex. "2xx", "5xx"
+	AvgLatencyMs    float64          `json:"avg_latency_ms"`
+	LatencyP50Ms    float64          `json:"latency_p50_ms"`
+	LatencyP90Ms    float64          `json:"latency_p90_ms"`
+	LatencyP99Ms    float64          `json:"latency_p99_ms"`
+	TotalDurationMs float64          `json:"total_duration_ms"`
+}
+
+// CustomMetric represents a metric recorded by the service through the custom metrics API.
+type CustomMetric struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Type        string            `json:"type"` // "counter", "gauge" or "histogram"
+	Labels      map[string]string `json:"labels,omitempty"`
+	Value       float64           `json:"value"`             // Value of the counter or gauge
+	Count       uint64            `json:"count,omitempty"`   // Number of observations of the histogram
+	Sum         float64           `json:"sum,omitempty"`     // Sum of the observations of the histogram
+	Buckets     []HistogramBucket `json:"buckets,omitempty"` // Cumulative buckets of the histogram, +Inf excluded
+	P50         float64           `json:"p50,omitempty"`     // Percentiles of the histogram estimated from the buckets
+	P90         float64           `json:"p90,omitempty"`
+	P99         float64           `json:"p99,omitempty"`
+}
+
+// HistogramBucket represents a cumulative bucket of a histogram.
+type HistogramBucket struct {
+	UpperBound float64 `json:"upper_bound"`
+	Count      uint64  `json:"count"`
 }
 
 // LoadStatistics represents the load statistics of the service.
@@ -43,9 +81,9 @@ type LoadStatistics struct {
 	SystemMemLoad        string `json:"system_memory_load"`
 	TotalMemLoad         string `json:"total_memory_load"`
 	OverallLoadOfService string `json:"overall_load_of_service"` // Final load of the service
-	// ServiceDiskLoad      string `json:"service_disk_load"`  @TODO: Need to work on this
-	// SystemDiskLoad       string `json:"system_disk_load"`   @TODO: Need to work on this
-	// TotalDiskLoad        string `json:"total_disk_load"`	   @TODO: Need to work on this
+	ServiceDiskLoad      string `json:"service_disk_load"`       // Share of the disk throughput done by the service
+	SystemDiskLoad       string `json:"system_disk_load"`        // Utilization of the busiest disk
+	TotalDiskLoad        string `json:"total_disk_load"`         // Used space of the volume holding the monigo data
 }
 
 // CPUStatistics represents the CPU statistics of the service.
@@ -102,29 +140,380 @@ type Record struct {
 
 // GoRoutinesStatistic represents the Go routines statistics.
 type GoRoutinesStatistic struct {
-	NumberOfGoroutines int      `json:"number_of_goroutines"`
-	StackView          []string `json:"stack_view"`
+	NumberOfGoroutines int              `json:"number_of_goroutines"`
+	MatchedGoroutines  int              `json:"matched_goroutines"`   // Goroutines matching the filter, every goroutine when none is given
+	States             map[string]int   `json:"states"`               // Number of goroutines by state, of every goroutine
+	StackView          []string         `json:"stack_view"`           // Stack traces of the matched goroutines
+	Groups             []GoroutineGroup `json:"groups,omitempty"`     // Matched goroutines grouped by identical stacks, the largest groups first
+	Goroutines         []Goroutine      `json:"goroutines,omitempty"` // Matched goroutines, only returned when they are not grouped
+}
+
+// Goroutine represents a goroutine parsed from a stack dump.
+type Goroutine struct {
+	ID                 int64            `json:"id"`
+	State              string           `json:"state"`                  // ex. "running", "chan receive" or "IO wait"
+	WaitMinutes        int              `json:"wait_minutes,omitempty"` // Time blocked as reported by the runtime, only from 1 minute
+	LockedToThread     bool             `json:"locked_to_thread,omitempty"`
+	Frames             []GoroutineFrame `json:"frames"` // Stack from the innermost call
+	FramesElided       bool             `json:"frames_elided,omitempty"`
+	CreatedBy          *GoroutineFrame  `json:"created_by,omitempty"`           // Go statement that created the goroutine, nil for the main goroutine
+	CreatorGoroutineID int64            `json:"creator_goroutine_id,omitempty"` // Goroutine that ran the go statement, reported since Go 1.21
+}
+
+// GoroutineFrame represents a function call in the stack of a goroutine.
+type GoroutineFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
 }
 
-// FunctionTraceDetails represents the function trace details.
+// GoroutineGroup represents the goroutines with an identical stack and creation site.
+type GoroutineGroup struct {
+	Count          int              `json:"count"`
+	States         map[string]int   `json:"states"` // Number of goroutines of the group by state
+	MinWaitMinutes int              `json:"min_wait_minutes"`
+	MaxWaitMinutes int              `json:"max_wait_minutes"`
+	Frames         []GoroutineFrame `json:"frames"`
+	CreatedBy      *GoroutineFrame  `json:"created_by,omitempty"`
+	IDs            []int64          `json:"ids"`
+}
+
+// GoroutineLeakReport represents the goroutine creation sites tracked by the leak detection.
+type GoroutineLeakReport struct {
+	Enabled                 bool                    `json:"enabled"`
+	IntervalSeconds         float64                 `json:"interval_seconds"`
+	BlockedThresholdSeconds float64                 `json:"blocked_threshold_seconds"`
+	Snapshots               int                     `json:"snapshots"` // Snapshots taken since the detection started
+	LastSnapshotAt          time.Time               `json:"last_snapshot_at"`
+	HealthPenalty           float64                 `json:"health_penalty"` // Percentage points subtracted from the service health for the suspects
+	Suspects                []GoroutineCreationSite `json:"suspects"`
+	Sites                   []GoroutineCreationSite `json:"sites,omitempty"` // Every tracked site, only returned when requested
+}
+
+// GoroutineCreationSite represents the goroutines created by a go statement across the snapshots of the leak detection.
+type GoroutineCreationSite struct {
+	CreatedBy      GoroutineFrame       `json:"created_by"`
+	Count          int                  `json:"count"`         // Goroutines in the last snapshot
+	Growth         int                  `json:"growth"`        // Change of the count over the history
+	BlockedCount   int                  `json:"blocked_count"` // Goroutines blocked beyond the threshold in the last snapshot
+	MaxWaitMinutes int                  `json:"max_wait_minutes"`
+	States         map[string]int       `json:"states"`
+	Frames         []GoroutineFrame     `json:"frames"`            // Stack of the most common goroutines of the site
+	History        []GoroutineSiteCount `json:"history"`           // Count in the recent snapshots, oldest first
+	Reasons        []string             `json:"reasons,omitempty"` // Why the site is suspected of leaking goroutines
+}
+
+// GoroutineSiteCount represents the number of goroutines of a creation site in a snapshot.
+type GoroutineSiteCount struct {
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+}
+
+// FunctionTraceDetails represents the reports of the CPU and heap profiles of a traced function.
 type FunctionTraceDetails struct {
-	FunctionName      string   `json:"function_name"`
-	CoreProfile       Profiles `json:"core_profile"`
-	FunctionCodeTrace string   `json:"function_code_trace"`
+	FunctionName string        `json:"function_name"`
+	ReportType   string        `json:"report_type"`
+	CPUProfile   ProfileReport `json:"cpu_profile"`
+	MemProfile   ProfileReport `json:"mem_profile"`
 }
 
-// Profiles represents the profiles.
-type Profiles struct {
-	CPU string `json:"cpu_profile"`
-	Mem string `json:"mem_profile"`
+// ProfileReport represents a top, list or tree report of a pprof profile.
+type ProfileReport struct {
+	Path       string            `json:"path"`
+	Type       string            `json:"type"`        // top, list or tree
+	SampleType string            `json:"sample_type"` // ex. cpu or inuse_space
+	Unit       string            `json:"unit"`        // Unit of the values, ex. nanoseconds or bytes
+	Total      int64             `json:"total"`       // Sum of the values of the samples reported
+	Top        []ProfileFunction `json:"top,omitempty"`
+	List       []ProfileSource   `json:"list,omitempty"`
+	Tree       []ProfileTreeNode `json:"tree,omitempty"`
+	Error      string            `json:"error,omitempty"` // Why the profile could not be reported, ex. when it was not recorded
 }
 
-// FunctionMetrics represents the function metrics.
+// ProfileFunction represents the samples of a function in a profile.
+type ProfileFunction struct {
+	Function    string  `json:"function"`
+	File        string  `json:"file"`
+	Flat        int64   `json:"flat"` // Value of the samples in the function itself
+	FlatPercent float64 `json:"flat_percent"`
+	Cum         int64   `json:"cum"` // Value of the samples in the function and the functions it calls
+	CumPercent  float64 `json:"cum_percent"`
+}
+
+// ProfileSource represents the samples of the lines of a function in a profile.
+type ProfileSource struct {
+	Function string              `json:"function"`
+	File     string              `json:"file"`
+	Flat     int64               `json:"flat"`
+	Cum      int64               `json:"cum"`
+	Lines    []ProfileSourceLine `json:"lines"`
+}
+
+// ProfileSourceLine represents the samples of a line of a function in a profile.
+type ProfileSourceLine struct {
+	Line   int64  `json:"line"`
+	Flat   int64  `json:"flat"`
+	Cum    int64  `json:"cum"`
+	Source string `json:"source,omitempty"` // Only available when the source file is
+}
+
+// ProfileTreeNode represents a function in a profile along with its callers and callees.
+type ProfileTreeNode struct {
+	ProfileFunction
+	Callers []ProfileEdge `json:"callers"`
+	Callees []ProfileEdge `json:"callees"`
+}
+
+// ProfileEdge represents the samples of the calls between two functions.
+type ProfileEdge struct {
+	Function string `json:"function"`
+	Value    int64  `json:"value"`
+}
+
+// ProfileMetadata represents a profile captured on demand or by the continuous profiler.
+type ProfileMetadata struct {
+	ID              string    `json:"id"`
+	Kind            string    `json:"kind"`   // cpu, heap, allocs, block, mutex, goroutine or trace
+	Source          string    `json:"source"` // on-demand or archive
+	CapturedAt      time.Time `json:"captured_at"`
+	DurationSeconds float64   `json:"duration_seconds,omitempty"` // Duration of the capture, zero for a snapshot
+	Rate            int       `json:"rate,omitempty"`             // Block profile rate or mutex profile fraction set for the capture
+	SizeBytes       int64     `json:"size_bytes"`
+	FileName        string    `json:"file_name"`
+	Path            string    `json:"path"`
+}
+
+// FlameGraph represents the flame graph of a pprof profile.
+type FlameGraph struct {
+	Path       string         `json:"path"`
+	SampleType string         `json:"sample_type"`
+	Unit       string         `json:"unit"`
+	Total      int64          `json:"total"`
+	Root       FlameGraphNode `json:"root"`
+}
+
+// FlameGraphNode represents a frame of a flame graph, the value is named as expected by the ECharts hierarchical series.
+type FlameGraphNode struct {
+	Name     string           `json:"name"`
+	Self     int64            `json:"self"`  // Value of the samples in the frame itself
+	Value    int64            `json:"value"` // Value of the samples in the frame and its children
+	Children []FlameGraphNode `json:"children,omitempty"`
+}
+
+// ProfileDiff represents the difference between a base and a target pprof profile, a positive delta being an increase in the target.
+type ProfileDiff struct {
+	Base        string                 `json:"base"`   // Profile id or path of the base profile
+	Target      string                 `json:"target"` // Profile id or path of the target profile
+	SampleType  string                 `json:"sample_type"`
+	Unit        string                 `json:"unit"`
+	BaseTotal   int64                  `json:"base_total"`
+	TargetTotal int64                  `json:"target_total"`
+	Normalized  bool                   `json:"normalized"` // Whether the base was scaled to the total of the target
+	Functions   []ProfileFunctionDelta `json:"functions"`  // Functions sorted by the largest change of their own value
+	FlameGraph  DiffFlameGraphNode     `json:"flame_graph"`
+}
+
+// ProfileFunctionDelta represents the change of the value of a function between two profiles.
+type ProfileFunctionDelta struct {
+	Function         string  `json:"function"`
+	File             string  `json:"file,omitempty"`
+	BaseFlat         int64   `json:"base_flat"`
+	BaseCum          int64   `json:"base_cum"`
+	TargetFlat       int64   `json:"target_flat"`
+	TargetCum        int64   `json:"target_cum"`
+	FlatDelta        int64   `json:"flat_delta"`
+	CumDelta         int64   `json:"cum_delta"`
+	FlatDeltaPercent float64 `json:"flat_delta_percent"` // Change as a percentage of the base total
+	CumDeltaPercent  float64 `json:"cum_delta_percent"`  // Change as a percentage of the base total
+}
+
+// DiffFlameGraphNode represents a frame of a differential flame graph, sized by the target profile like a flame graph
+// and colored by the delta. The frames only found in the base have a value of zero.
+type DiffFlameGraphNode struct {
+	Name      string               `json:"name"`
+	Self      int64                `json:"self"`       // Value of the samples in the frame itself in the target
+	Value     int64                `json:"value"`      // Value of the samples in the frame and its children in the target
+	BaseSelf  int64                `json:"base_self"`  // Value of the samples in the frame itself in the base
+	BaseValue int64                `json:"base_value"` // Value of the samples in the frame and its children in the base
+	Delta     int64                `json:"delta"`      // Value minus base value
+	Children  []DiffFlameGraphNode `json:"children,omitempty"`
+}
+
+// FunctionMetrics represents the function metrics, aggregated across the calls of the function.
 type FunctionMetrics struct {
+	Name               string        `json:"name"`
 	FunctionLastRanAt  time.Time     `json:"function_last_ran_at"`
 	CPUProfileFilePath string        `json:"cpu_profile_file_path"`
 	MemProfileFilePath string        `json:"mem_profile_file_path"`
-	MemoryUsage        uint64        `json:"memory_usage"`
-	GoroutineCount     int           `json:"goroutine_count"`
-	ExecutionTime      time.Duration `json:"execution_time"`
+	MemoryUsage        uint64        `json:"memory_usage"`    // Bytes allocated on the heap during the last call, including by the other goroutines meanwhile
+	GoroutineCount     int           `json:"goroutine_count"` // Goroutines left running by the last call
+	ExecutionTime      time.Duration `json:"execution_time"`  // Execution time of the last call
+	Error              string        `json:"error,omitempty"` // Error returned by the last call
+
+	CallCount         int64                `json:"call_count"`
+	ErrorCount        int64                `json:"error_count"` // Calls that returned an error or panicked
+	PanicCount        int64                `json:"panic_count"`
+	ProfiledCallCount int64                `json:"profiled_call_count"` // Calls that were CPU profiled, a single call is profiled at a time
+	ExecutionTimeMs   SummaryStatistics    `json:"execution_time_ms"`
+	AllocatedBytes    SummaryStatistics    `json:"allocated_bytes"`    // Only measured by TraceFunction
+	Parents           map[string]int64     `json:"parents,omitempty"`  // Calls made within traced parent functions, by parent name
+	Profiles          []FunctionProfile    `json:"profiles,omitempty"` // Profiles of the most recent profiled calls, the latest first
+	Trend             []FunctionTrendPoint `json:"trend,omitempty"`    // Stored statistics, only returned when a trend is requested
+}
+
+// FunctionProfile represents the profile files of a profiled call of a function.
+type FunctionProfile struct {
+	CalledAt           time.Time `json:"called_at"`
+	CPUProfileFilePath string    `json:"cpu_profile_file_path"`
+	MemProfileFilePath string    `json:"mem_profile_file_path,omitempty"` // Empty when the heap profile could not be written
+}
+
+// SummaryStatistics represents the distribution of a value across calls, the percentiles are of the most recent calls.
+type SummaryStatistics struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+}
+
+// FunctionTrendPoint represents the statistics of a function stored at a point in time.
+type FunctionTrendPoint struct {
+	Time   time.Time          `json:"time"`
+	Values map[string]float64 `json:"values"` // Stored value per metric ex. "function_execution_time_p95"
+}
+
+// SamplerStatistics is the struct to store the overhead of collecting the service statistics
+type SamplerStatistics struct {
+	SampledAt         time.Time `json:"sampled_at"`          // Time the statistics were collected
+	IntervalMs        float64   `json:"interval_ms"`         // Time between two collections of the sampler, 0 when collected on demand
+	CollectionCount   int64     `json:"collection_count"`    // Number of collections since the service started
+	LastCollectionMs  float64   `json:"last_collection_ms"`  // Time taken by the last collection
+	AvgCollectionMs   float64   `json:"avg_collection_ms"`   // Average time taken by a collection
+	MaxCollectionMs   float64   `json:"max_collection_ms"`   // Longest time taken by a collection
+	TotalCollectionMs float64   `json:"total_collection_ms"` // Time spent collecting since the service started
+	OverheadPercent   float64   `json:"overhead_percent"`    // Share of the interval spent collecting the statistics
+}
+
+// CollectorStatus is the struct to store the status of a collector of the service statistics, ex. cpu or network
+type CollectorStatus struct {
+	Name       string `json:"name"`                 // Name of the collector
+	Available  bool   `json:"available"`            // False when the last collection failed
+	LastError  string `json:"last_error,omitempty"` // Error of the last collection, if it failed
+	ErrorCount int64  `json:"error_count"`          // Number of failed collections since the service started
+}
+
+// ContainerStatistics is the struct to store the cgroup limits and usage of the service, ex. inside a Kubernetes pod
+type ContainerStatistics struct {
+	CgroupVersion int             `json:"cgroup_version"` // 1 or 2, 0 when the service does not run in a cgroup
+	Memory        ContainerMemory `json:"memory"`
+	CPU           ContainerCPU    `json:"cpu"`
+}
+
+// ContainerMemory is the struct to store the memory limit and usage of the cgroup
+type ContainerMemory struct {
+	LimitBytes      uint64  `json:"limit_bytes"`       // 0 when the memory is not limited
+	UsageBytes      uint64  `json:"usage_bytes"`       // Memory used by the cgroup, including the page cache
+	WorkingSetBytes uint64  `json:"working_set_bytes"` // Memory used by the cgroup without the inactive page cache, which is reclaimed before an OOM kill
+	UsagePercent    float64 `json:"usage_percent"`     // Working set of the limit, 0 when the memory is not limited
+	OOMEvents       uint64  `json:"oom_events"`        // Times the limit was reached
+	OOMKills        uint64  `json:"oom_kills"`         // Processes killed by the OOM killer
+}
+
+// ContainerCPU is the struct to store the CPU limit, usage and throttling of the cgroup
+type ContainerCPU struct {
+	LimitCores       float64 `json:"limit_cores"`       // Quota divided by the period, 0 when the CPU is not limited
+	UsageSeconds     float64 `json:"usage_seconds"`     // CPU time used by the cgroup
+	UsageCores       float64 `json:"usage_cores"`       // Cores used since the previous collection
+	UsagePercent     float64 `json:"usage_percent"`     // Usage of the limit, 0 when the CPU is not limited
+	Periods          uint64  `json:"periods"`           // Enforcement periods elapsed
+	ThrottledPeriods uint64  `json:"throttled_periods"` // Periods the cgroup was throttled in
+	ThrottledSeconds float64 `json:"throttled_seconds"` // Time the cgroup was throttled for
+	ThrottledPercent float64 `json:"throttled_percent"` // Share of the periods the cgroup was throttled in
+}
+
+// DiskIOStatistics is the struct to store the disk I/O of the service and the devices, and the usage of the volume holding the monigo data
+type DiskIOStatistics struct {
+	Process    ProcessIO       `json:"process"`
+	Devices    []DeviceIO      `json:"devices"`
+	Filesystem FilesystemUsage `json:"filesystem"`
+}
+
+// ProcessIO is the struct to store the disk I/O of the service, read from /proc/self/io on Linux
+type ProcessIO struct {
+	ReadBytes        uint64  `json:"read_bytes"`
+	WriteBytes       uint64  `json:"write_bytes"`
+	ReadOps          uint64  `json:"read_ops"`
+	WriteOps         uint64  `json:"write_ops"`
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"` // Rates since the previous collection
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+}
+
+// DeviceIO is the struct to store the disk I/O of a device, partitions and virtual devices are excluded
+type DeviceIO struct {
+	Name               string  `json:"name"`
+	ReadBytes          uint64  `json:"read_bytes"`
+	WriteBytes         uint64  `json:"write_bytes"`
+	ReadOps            uint64  `json:"read_ops"`
+	WriteOps           uint64  `json:"write_ops"`
+	ReadBytesPerSec    float64 `json:"read_bytes_per_sec"` // Rates since the previous collection
+	WriteBytesPerSec   float64 `json:"write_bytes_per_sec"`
+	ReadOpsPerSec      float64 `json:"read_ops_per_sec"`
+	WriteOpsPerSec     float64 `json:"write_ops_per_sec"`
+	UtilizationPercent float64 `json:"utilization_percent"` // Percentage of the time the device was busy
+}
+
+// FilesystemUsage is the struct to store the usage of the filesystem holding a path
+type FilesystemUsage struct {
+	Path              string  `json:"path"`
+	Fstype            string  `json:"fstype"`
+	TotalBytes        uint64  `json:"total_bytes"`
+	UsedBytes         uint64  `json:"used_bytes"`
+	FreeBytes         uint64  `json:"free_bytes"`
+	UsedPercent       float64 `json:"used_percent"`
+	InodesUsedPercent float64 `json:"inodes_used_percent"`
+}
+
+// NetworkIOStatistics is the struct to store the network I/O of the interfaces, the excluded interfaces are left out of the totals
+type NetworkIOStatistics struct {
+	BytesSent           float64       `json:"bytes_sent"`
+	BytesReceived       float64       `json:"bytes_received"`
+	BytesSentPerSec     float64       `json:"bytes_sent_per_sec"` // Rates since the previous collection
+	BytesReceivedPerSec float64       `json:"bytes_received_per_sec"`
+	Interfaces          []InterfaceIO `json:"interfaces"`
+}
+
+// InterfaceIO is the struct to store the network I/O of an interface
+type InterfaceIO struct {
+	Name                string  `json:"name"`
+	BytesSent           uint64  `json:"bytes_sent"`
+	BytesReceived       uint64  `json:"bytes_received"`
+	PacketsSent         uint64  `json:"packets_sent"`
+	PacketsReceived     uint64  `json:"packets_received"`
+	ErrorsIn            uint64  `json:"errors_in"`
+	ErrorsOut           uint64  `json:"errors_out"`
+	DropsIn             uint64  `json:"drops_in"`
+	DropsOut            uint64  `json:"drops_out"`
+	BytesSentPerSec     float64 `json:"bytes_sent_per_sec"` // Rates since the previous collection
+	BytesReceivedPerSec float64 `json:"bytes_received_per_sec"`
+}
+
+// RuntimeStatistics is the struct to store the GC, scheduler and heap statistics read from runtime/metrics
+type RuntimeStatistics struct {
+	SchedulerLatency RuntimeHistogram `json:"scheduler_latency"`  // Time goroutines spent runnable before running
+	GCPauses         RuntimeHistogram `json:"gc_pauses"`          // Stop-the-world pauses of the GC
+	HeapGoalBytes    uint64           `json:"heap_goal_bytes"`    // Heap size the GC aims to finish the cycle at
+	HeapLiveBytes    uint64           `json:"heap_live_bytes"`    // Heap marked live by the previous GC cycle
+	MemoryLimitBytes uint64           `json:"memory_limit_bytes"` // Soft memory limit set by GOMEMLIMIT or debug.SetMemoryLimit, 0 when not set
+	GCCycles         uint64           `json:"gc_cycles"`
+	MutexWaitMs      float64          `json:"mutex_wait_ms"` // Time goroutines spent blocked on a sync.Mutex or sync.RWMutex
+}
+
+// RuntimeHistogram is the struct to store a runtime latency histogram
+type RuntimeHistogram struct {
+	Count   uint64            `json:"count"`
+	Buckets []HistogramBucket `json:"buckets"` // Cumulative buckets with the upper bounds in seconds, +Inf excluded
+	P50Ms   float64           `json:"p50_ms"`  // Percentiles of the observations since the previous collection
+	P90Ms   float64           `json:"p90_ms"`
+	P99Ms   float64           `json:"p99_ms"`
 }
@@ -8,10 +8,11 @@ import (
 
 // ServiceInfo is the struct to store the service information
 type ServiceInfo struct {
-	ServiceName      string    `json:"service_name"`
-	ServiceStartTime time.Time `json:"service_start_time"`
-	GoVersion        string    `json:"go_version"`
-	ProcessId        int32     `json:"process_id"`
+	ServiceName      string            `json:"service_name"`
+	ServiceStartTime time.Time         `json:"service_start_time"`
+	GoVersion        string            `json:"go_version"`
+	ProcessId        int32             `json:"process_id"`
+	Labels           map[string]string `json:"labels"` // Default labels the metrics of the service are stored with
 }
 
 // ServiceHealthThresholds is the struct to store the service health thresholds
@@ -23,9 +24,10 @@ type ServiceHealthThresholds struct {
 
 // FetchDataPoints is the struct to fetch the data points from the storage
 type FetchDataPoints struct {
-	FieldName []string `json:"field_name"`
-	StartTime string   `json:"start_time"` // "2006-01-02T15:04:05Z07:00"
-	EndTime   string   `json:"end_time"`   // "2006-01-02T15:04:05Z07:00"
+	FieldName []string          `json:"field_name"`
+	StartTime string            `json:"start_time"`       // "2006-01-02T15:04:05Z07:00"
+	EndTime   string            `json:"end_time"`         // "2006-01-02T15:04:05Z07:00"
+	Labels    map[string]string `json:"labels,omitempty"` // Replaces the default labels with the same name, ex. {"host": "server2"} or the labels of a custom metric
 }
 
 // DataPointsInfo is the struct to store the data points information
@@ -36,10 +38,11 @@ type DataPointsInfo struct {
 
 // ReportsRequest is the struct to store the reports request
 type ReportsRequest struct {
-	Topic     string `json:"topic"`
-	StartTime string `json:"start_time"` // "2006-01-02T15:04:05Z07:00"
-	EndTime   string `json:"end_time"`   // "2006-01-02T15:04:05Z07:00"
-	TimeFrame string `json:"time_frame"`
+	Topic     string            `json:"topic"`
+	StartTime string            `json:"start_time"` // "2006-01-02T15:04:05Z07:00"
+	EndTime   string            `json:"end_time"`   // "2006-01-02T15:04:05Z07:00"
+	TimeFrame string            `json:"time_frame"`
+	Labels    map[string]string `json:"labels,omitempty"` // Replaces the default labels with the same name, ex. {"instance_id": "api-2"}
 }
 
 // SystemHealthInPercent is the struct to store the system health in percentage
@@ -53,3 +56,11 @@ type HealthFields struct {
 	AllowedByUser float64 `json:"allowed_by_user"`
 	Message       string  `json:"message"`
 }
+
+// ServiceEvent is the struct to store an event of the service lifecycle, ex. a restart after a deploy
+type ServiceEvent struct {
+	Type              string            `json:"type"`                // service_start, service_restart or service_stop
+	Time              time.Time         `json:"time"`                // Time the event occurred
+	Labels            map[string]string `json:"labels"`              // Labels the metrics were stored with, query them to compare before and after the event
+	PreviousStartTime time.Time         `json:"previous_start_time"` // Start time of the previous run, set on service_restart
+}
@@ -1,13 +1,17 @@
 package monigo
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,15 +30,14 @@ var (
 	baseAPIPath = "/monigo/api/v1"               // Base API path for the dashboard
 )
 
-func init() {
-	BasePath = common.GetBasePath() // Get the base path for the monigo
-}
+const shutdownTimeout = 10 * time.Second // Time given to the monigo service to stop once the context passed to Start is done
 
 // Monigo is the main struct to start the monigo service
 type Monigo struct {
 	ServiceName             string    `json:"service_name"`       // Mandatory field ex. "backend", "OrderAPI", "PaymentService", etc.
 	DashboardPort           int       `json:"dashboard_port"`     // Default is 8080
 	DataPointsSyncFrequency string    `json:"db_sync_frequency"`  // Default is 5 Minutes
+	SamplingInterval        string    `json:"sampling_interval"`  // Default is 5 Seconds, the APIs serve the statistics collected at this interval
 	DataRetentionPeriod     string    `json:"retention_period"`   // Default is 7 Day
 	TimeZone                string    `json:"time_zone"`          // Default is Local
 	GoVersion               string    `json:"go_version"`         // Dynamically set from runtime.Version()
@@ -43,11 +46,42 @@ type Monigo struct {
 	MaxCPUUsage             float64   `json:"max_cpu_usage"`      // Default is 95%, You can set it to 100% if you want to monitor 100% CPU usage
 	MaxMemoryUsage          float64   `json:"max_memory_usage"`   // Default is 95%, You can set it to 100% if you want to monitor 100% Memory usage
 	MaxGoRoutines           int       `json:"max_go_routines"`    // Default is 100, You can set it to any number based on your service
+
+	Hostname       string            `json:"hostname"`        // Default is the hostname reported by the OS
+	InstanceID     string            `json:"instance_id"`     // Default is the hostname, reported but not stored, set it in Labels to store it
+	ServiceVersion string            `json:"service_version"` // Optional, ex. "v1.4.2"
+	Labels         map[string]string `json:"labels"`          // Optional static labels every metric is stored with ex. {"region": "eu-west-1"}, they override the default labels with the same name
+
+	Storage     timeseries.Storage `json:"-"`            // Default is the disk-backed tstorage under <base path>/data, use timeseries.NewMemoryStorage for tests
+	PersistData bool               `json:"persist_data"` // Default is false and the stored data is purged on start, set it to true to keep the data of the previous runs for the retention period
+
+	ContinuousProfiling            bool   `json:"continuous_profiling"`              // Default is false, set it to true to archive a short CPU profile and a heap profile every interval
+	ContinuousProfilingInterval    string `json:"continuous_profiling_interval"`     // Default is 1 Minute, the time between two captures of the continuous profiler
+	ContinuousProfilingCPUDuration string `json:"continuous_profiling_cpu_duration"` // Default is 10 Seconds, the duration of the archived CPU profiles
+	ProfileArchiveMaxSizeMB        int    `json:"profile_archive_max_size_mb"`       // Default is 256 MB, the oldest archived profiles are removed beyond it
+
+	GoroutineLeakDetection        bool   `json:"goroutine_leak_detection"`         // Default is false, set it to true to snapshot the goroutines every interval and flag the creation sites leaking goroutines
+	GoroutineLeakInterval         string `json:"goroutine_leak_interval"`          // Default is 1 Minute, the growth of a creation site is evaluated over the last 30 snapshots
+	GoroutineLeakBlockedThreshold string `json:"goroutine_leak_blocked_threshold"` // Default is 10 Minutes, the goroutines blocked for longer are counted as blocked
+
+	CgroupRoot         string   `json:"cgroup_root"`         // Default is /sys/fs/cgroup, the container limits and usage are read from this directory
+	ExcludedInterfaces []string `json:"excluded_interfaces"` // Default is the loopback interfaces, patterns ex. "docker0" or "veth*" left out of the network I/O, an empty list excludes none
+
+	DisableDashboardServer bool       `json:"disable_dashboard_server"` // Default is false, set it to true when serving monigo.Handler from your own server
+	Authorizer             Authorizer `json:"-"`                        // Default is no authentication, protects the dashboard and the read-only APIs ex. monigo.BasicAuth
+	ControlAuthorizer      Authorizer `json:"-"`                        // Default is the Authorizer, protects the control APIs ex. monigo.BearerToken
+	TLSCertFile            string     `json:"tls_cert_file"`            // Default is plain HTTP, serves the dashboard over TLS along with TLSKeyFile
+	TLSKeyFile             string     `json:"tls_key_file"`             // Private key matching TLSCertFile
+
+	server *http.Server  // Dashboard server, set while the monigo service is running
+	done   chan struct{} // Closed by Stop to unblock Start
+	mu     sync.Mutex    // Guards the dashboard server
 }
 
 // MonigoInt is the interface to start the monigo service
 type MonigoInt interface {
-	Start()                                         // Purge the monigo storage
+	Start(ctx context.Context)                      // Start the monigo service, blocks until the context is done or Stop is called
+	Stop(ctx context.Context) error                 // Stop the monigo service gracefully
 	GetGoRoutinesStats() models.GoRoutinesStatistic // Print the Go routines stats
 }
 
@@ -75,7 +109,8 @@ func setDashboardPort(m *Monigo) {
 		m.DashboardPort = defaultPort
 		listener, err = net.Listen("tcp", fmt.Sprintf(":%d", m.DashboardPort))
 		if err != nil {
-			log.Panicf("[MoniGo] Failed to bind to default port %d: %v\n", defaultPort, err)
+			log.Printf("[MoniGo] Failed to bind to default port %d: %v\n", defaultPort, err)
+			return // The dashboard server reports the error, the metrics are still collected
 		}
 	}
 	defer listener.Close()
@@ -94,9 +129,17 @@ func (m *Monigo) MonigoInstanceConstructor() {
 		location = time.Local
 	}
 
-	setDashboardPort(m) // Setting the dashboard port
+	if !m.DisableDashboardServer {
+		setDashboardPort(m) // Setting the dashboard port
+	}
 	m.DataPointsSyncFrequency = common.DefaultIfEmpty(m.DataPointsSyncFrequency, "5m")
+	m.SamplingInterval = common.DefaultIfEmpty(m.SamplingInterval, "5s")
 	m.DataRetentionPeriod = common.DefaultIfEmpty(m.DataRetentionPeriod, "7d")
+	m.ContinuousProfilingInterval = common.DefaultIfEmpty(m.ContinuousProfilingInterval, "1m")
+	m.ContinuousProfilingCPUDuration = common.DefaultIfEmpty(m.ContinuousProfilingCPUDuration, "10s")
+	m.ProfileArchiveMaxSizeMB = common.DefaultIntIfZero(m.ProfileArchiveMaxSizeMB, 256)
+	m.GoroutineLeakInterval = common.DefaultIfEmpty(m.GoroutineLeakInterval, "1m")
+	m.GoroutineLeakBlockedThreshold = common.DefaultIfEmpty(m.GoroutineLeakBlockedThreshold, "10m")
 	m.MaxCPUUsage = common.DefaultFloatIfZero(m.MaxCPUUsage, 95)
 	m.MaxMemoryUsage = common.DefaultFloatIfZero(m.MaxMemoryUsage, 95)
 	m.MaxGoRoutines = common.DefaultIntIfZero(m.MaxGoRoutines, 100)
@@ -110,43 +153,135 @@ func (m *Monigo) MonigoInstanceConstructor() {
 	m.ServiceStartTime = time.Now().In(location) // Setting the service start time
 }
 
-// Function to start the monigo service
-func (m *Monigo) Start() {
-	// Validate service name
-	if m.ServiceName == "" {
-		log.Panic("[MoniGo] service_name is required, please provide the service name")
+// defaultLabels returns the labels every metric of the service is stored with, and the labels of the instance
+// reported alongside but not stored, as the storage would no longer match the earlier series after a deploy
+func (m *Monigo) defaultLabels() (map[string]string, map[string]string) {
+	if m.Hostname == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Println("[MoniGo] Error getting the hostname. Setting to localhost, Error: ", err)
+			hostname = "localhost"
+		}
+		m.Hostname = hostname
 	}
+	m.InstanceID = common.DefaultIfEmpty(m.InstanceID, m.Hostname)
 
-	m.MonigoInstanceConstructor()
-	timeseries.PurgeStorage() // Purge storage and set sync frequency for metrics
-	if err := timeseries.SetDataPointsSyncFrequency(m.DataPointsSyncFrequency); err != nil {
-		log.Panic("[MoniGo] failed to set data points sync frequency: ", err)
+	labels := map[string]string{
+		"host":    m.Hostname,
+		"service": m.ServiceName,
+	}
+	instanceLabels := map[string]string{
+		"instance_id": m.InstanceID,
+		"version":     m.ServiceVersion, // Dropped when empty
+	}
+	for name, value := range m.Labels {
+		labels[name] = value
+		delete(instanceLabels, name) // A static label is stored, ex. to tell apart instances sharing a storage
 	}
+	return labels, instanceLabels
+}
 
-	// Fetching runtime details
-	m.ProcessId = common.GetProcessId()
-	m.GoVersion = runtime.Version()
+// archiveConfig returns the configuration of the continuous profiler, the invalid durations falling back to the defaults
+func (m *Monigo) archiveConfig() core.ArchiveConfig {
+	interval, err := time.ParseDuration(m.ContinuousProfilingInterval)
+	if err != nil || interval <= 0 {
+		log.Printf("[MoniGo] Invalid continuous profiling interval %q. Using default of 1m.\n", m.ContinuousProfilingInterval)
+		interval = core.DefaultArchiveInterval
+	}
+	cpuDuration, err := time.ParseDuration(m.ContinuousProfilingCPUDuration)
+	if err != nil || cpuDuration <= 0 {
+		log.Printf("[MoniGo] Invalid continuous profiling CPU duration %q. Using default of 10s.\n", m.ContinuousProfilingCPUDuration)
+		cpuDuration = core.DefaultArchiveCPUDuration
+	}
+	return core.ArchiveConfig{
+		Interval:    interval,
+		CPUDuration: cpuDuration,
+		MaxSize:     int64(m.ProfileArchiveMaxSizeMB) << 20,
+	}
+}
+
+// leakDetectionConfig returns the configuration of the goroutine leak detection, the invalid durations falling back to the defaults
+func (m *Monigo) leakDetectionConfig() core.LeakDetectionConfig {
+	interval, err := time.ParseDuration(m.GoroutineLeakInterval)
+	if err != nil || interval <= 0 {
+		log.Printf("[MoniGo] Invalid goroutine leak interval %q. Using default of 1m.\n", m.GoroutineLeakInterval)
+		interval = core.DefaultLeakInterval
+	}
+	blockedThreshold, err := time.ParseDuration(m.GoroutineLeakBlockedThreshold)
+	if err != nil || blockedThreshold <= 0 {
+		log.Printf("[MoniGo] Invalid goroutine leak blocked threshold %q. Using default of 10m.\n", m.GoroutineLeakBlockedThreshold)
+		blockedThreshold = core.DefaultLeakBlockedThreshold
+	}
+	return core.LeakDetectionConfig{Interval: interval, BlockedThreshold: blockedThreshold}
+}
 
+// updateStartTimeCache records the service start time in the cache under the base path, returning the previous one.
+// The previous one is reported by the restart event when the data is persisted.
+func (m *Monigo) updateStartTimeCache() (previous time.Time, restarted bool) {
 	cachePath := BasePath + "/cache.dat"
 	cache := common.Cache{Data: make(map[string]time.Time)}
 	if err := cache.LoadFromFile(cachePath); err != nil {
-		log.Panic("[MoniGo] failed to load cache from file: ", err)
+		log.Println("[MoniGo] failed to load cache from file: ", err)
 	}
 
-	// Updating the service start time in the cache
-	if startTime, exists := cache.Data[m.ServiceName]; exists {
-		m.ServiceStartTime = startTime
-	} else {
-		m.ServiceStartTime = time.Now()
-		cache.Data[m.ServiceName] = m.ServiceStartTime
-	}
+	previous, restarted = cache.Data[m.ServiceName]
+	cache.Data[m.ServiceName] = m.ServiceStartTime
 
 	// Save the cache data to file
 	if err := cache.SaveToFile(cachePath); err != nil {
-		log.Panic("[MoniGo] error saving cache to file: ", err)
+		log.Println("[MoniGo] error saving cache to file: ", err)
+	}
+	return previous, restarted
+}
+
+// recordsEvents reports whether the lifecycle events are recorded, they are kept under the monigo folder with the persisted data
+func (m *Monigo) recordsEvents() bool {
+	return m.PersistData && m.Storage == nil
+}
+
+// Start starts the monigo service and the dashboard, it blocks until the context is done or Stop is called.
+// When the context is done the monigo service is stopped gracefully.
+func (m *Monigo) Start(ctx context.Context) {
+	// Validate service name
+	if m.ServiceName == "" {
+		log.Panic("[MoniGo] service_name is required, please provide the service name")
+	}
+
+	if (m.TLSCertFile == "") != (m.TLSKeyFile == "") {
+		log.Panic("[MoniGo] tls_cert_file and tls_key_file must be provided together")
+	}
+
+	m.MonigoInstanceConstructor()
+
+	// Fetching runtime details
+	m.ProcessId = common.GetProcessId()
+	m.GoVersion = runtime.Version()
+
+	labels, instanceLabels := m.defaultLabels()
+	timeseries.SetDefaultLabels(labels) // Setting the labels before the first data points are stored
+	timeseries.SetInstanceLabels(instanceLabels)
+	core.SetCgroupRoot(m.CgroupRoot)
+	core.SetExcludedInterfaces(m.ExcludedInterfaces)
+	if m.Storage != nil {
+		timeseries.SetStorage(m.Storage) // Using the storage provided by the user instead of the disk-backed storage
 	}
 
-	// Setting common service information
+	// The monigo folder holds the disk-backed storage, a storage provided by the user leaves it untouched:
+	// the folder is not purged, and the start time cache and the lifecycle events are not kept
+	BasePath = common.GetBasePath() // Get the base path for the monigo
+	var previousStartTime time.Time
+	var restarted bool
+	if m.Storage == nil {
+		if !m.PersistData {
+			if err := timeseries.PurgeStorage(); err != nil { // Purging the data of the previous runs
+				log.Println("[MoniGo] error purging the storage: ", err)
+			}
+		}
+		previousStartTime, restarted = m.updateStartTimeCache()
+	}
+	common.SetDataPersistence(m.PersistData)
+
+	// Setting common service information, the retention period is used when the storage is initialized
 	common.SetServiceInfo(
 		m.ServiceName,
 		m.ServiceStartTime,
@@ -155,9 +290,134 @@ func (m *Monigo) Start() {
 		m.DataRetentionPeriod,
 	)
 
-	if err := StartDashboard(m.DashboardPort); err != nil {
-		log.Panic("[MoniGo] error starting the dashboard: ", err)
+	if m.recordsEvents() {
+		event := models.ServiceEvent{Type: timeseries.ServiceStartEvent, Time: m.ServiceStartTime, Labels: timeseries.DefaultLabelsMap()}
+		if restarted {
+			event.Type = timeseries.ServiceRestartEvent
+			event.PreviousStartTime = previousStartTime
+		}
+		if err := timeseries.RecordEvent(event); err != nil {
+			log.Println("[MoniGo] error recording the service start event: ", err)
+		}
+	}
+
+	samplingInterval, err := time.ParseDuration(m.SamplingInterval)
+	if err != nil || samplingInterval <= 0 {
+		log.Printf("[MoniGo] Invalid sampling interval %q. Using default of 5s.\n", m.SamplingInterval)
+		samplingInterval = 5 * time.Second
+	}
+	core.StartSampler(samplingInterval) // Collecting the statistics in the background before the first data points are stored
+
+	if m.ContinuousProfiling {
+		core.StartContinuousProfiler(m.archiveConfig())
 	}
+	if m.GoroutineLeakDetection {
+		core.StartLeakDetection(m.leakDetectionConfig())
+	}
+
+	if err := timeseries.SetDataPointsSyncFrequency(m.DataPointsSyncFrequency); err != nil {
+		log.Println("[MoniGo] failed to set data points sync frequency: ", err)
+	}
+
+	done := make(chan struct{})
+	var server *http.Server
+	var serverErr chan error // Stays nil when the dashboard server is disabled
+	if !m.DisableDashboardServer {
+		server = &http.Server{
+			Addr:    fmt.Sprintf(":%d", m.DashboardPort),
+			Handler: newDashboardMux(m.authorizers()),
+		}
+	}
+
+	m.mu.Lock()
+	m.server = server
+	m.done = done
+	m.mu.Unlock()
+
+	if server != nil {
+		serverErr = make(chan error, 1)
+		go func() {
+			if m.TLSCertFile != "" {
+				serverErr <- server.ListenAndServeTLS(m.TLSCertFile, m.TLSKeyFile)
+				return
+			}
+			serverErr <- server.ListenAndServe()
+		}()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			stopCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if err := m.Stop(stopCtx); err != nil {
+				log.Println("[MoniGo] error stopping the monigo service: ", err)
+			}
+			return
+		case <-done: // Stopped by Stop
+			return
+		case err := <-serverErr:
+			if !errors.Is(err, http.ErrServerClosed) {
+				log.Println("[MoniGo] error starting the dashboard, the metrics are still collected: ", err)
+			}
+			serverErr = nil // The dashboard is down, waiting for the service to be stopped
+		}
+	}
+}
+
+// Stop stops the monigo service gracefully. It shuts down the dashboard, stops the data points sync,
+// stores a final sample of the service metrics and closes the storage, unless it was provided through Storage.
+// The storage is left open when ctx expires before the final sample is stored.
+func (m *Monigo) Stop(ctx context.Context) error {
+	var errs []error
+
+	m.mu.Lock()
+	server := m.server
+	m.server = nil
+	if m.done != nil {
+		close(m.done)
+		m.done = nil
+	}
+	m.mu.Unlock()
+
+	if server != nil {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("error shutting down the dashboard: %w", err))
+		}
+	}
+
+	timeseries.StopDataPointsSync()
+	core.StopSampler()
+	core.StopContinuousProfiler()
+	core.StopLeakDetection()
+
+	flushErr := make(chan error, 1)
+	go func() {
+		serviceMetrics := core.CollectServiceStats()
+		flushErr <- timeseries.StoreServiceMetrics(&serviceMetrics)
+	}()
+
+	select {
+	case err := <-flushErr:
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error storing the final service metrics: %w", err))
+		}
+		if err := timeseries.CloseStorage(); err != nil {
+			errs = append(errs, err)
+		}
+	case <-ctx.Done():
+		// The final metrics are still being inserted, the storage is left open rather than closed under the insert
+		errs = append(errs, fmt.Errorf("error storing the final service metrics, the storage was left open: %w", ctx.Err()))
+	}
+
+	if m.recordsEvents() {
+		event := models.ServiceEvent{Type: timeseries.ServiceStopEvent, Time: time.Now(), Labels: timeseries.DefaultLabelsMap()}
+		if err := timeseries.RecordEvent(event); err != nil {
+			errs = append(errs, fmt.Errorf("error recording the service stop event: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 // GetGoRoutinesStats get back the Go routines stats from the core package
@@ -170,33 +430,138 @@ func TraceFunction(f func()) {
 	core.TraceFunction(f)
 }
 
-// StartDashboard starts the dashboard on the specified port
+// TraceFunctionCtx traces the function under the given name, recording its execution time and error.
+// Functions traced with the context passed to fn are recorded as its children.
+func TraceFunctionCtx(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return core.TraceFunctionCtx(ctx, name, fn)
+}
+
+// Trace traces the function under the given name like TraceFunctionCtx, returning the result of the function.
+func Trace[T any](ctx context.Context, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	return core.Trace(ctx, name, fn)
+}
+
+// SetBlockProfileRate sets the block profile rate like runtime.SetBlockProfileRate. Use it instead of the runtime
+// so a block profile captured on demand with another rate restores this one afterwards.
+func SetBlockProfileRate(rate int) {
+	core.SetBlockProfileRate(rate)
+}
+
+// StartDashboard starts the dashboard on the specified port.
+// The dashboard is served without authentication, use the StartDashboard method of the Monigo instance to protect it.
 func StartDashboard(port int) error {
+	return (&Monigo{}).StartDashboard(port)
+}
+
+// StartDashboard starts the dashboard on the specified port, protected by the Authorizer and the ControlAuthorizer
+func (m *Monigo) StartDashboard(port int) error {
 
 	if port == 0 {
 		port = 8080 // Default port for the dashboard
 	}
 
-	// HTML site
-	http.HandleFunc("/", serveHtmlSite)
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), newDashboardMux(m.authorizers())); err != nil {
+		return fmt.Errorf("error starting the dashboard: %v", err)
+	}
 
-	// API to get Service Statistics
-	http.HandleFunc(fmt.Sprintf("%s/metrics", baseAPIPath), api.GetServiceStatistics)
+	return nil
+}
 
-	// Service APIs
-	http.HandleFunc(fmt.Sprintf("%s/service-info", baseAPIPath), api.GetServiceInfoAPI)
-	http.HandleFunc(fmt.Sprintf("%s/service-metrics", baseAPIPath), api.GetServiceMetricsFromStorage)
-	http.HandleFunc(fmt.Sprintf("%s/go-routines-stats", baseAPIPath), api.GetGoRoutinesStats)
-	http.HandleFunc(fmt.Sprintf("%s/function", baseAPIPath), api.GetFunctionTraceDetails)
-	http.HandleFunc(fmt.Sprintf("%s/function-details", baseAPIPath), api.ViewFunctionMaetrtics)
+// Handler returns the dashboard and every monigo API served under the prefix, without authentication.
+// Use the Handler method of the Monigo instance to protect them with its Authorizer and ControlAuthorizer.
+func Handler(prefix string) http.Handler {
+	return (&Monigo{}).Handler(prefix)
+}
 
-	// Reports
-	http.HandleFunc(fmt.Sprintf("%s/reports", baseAPIPath), api.GetReportData)
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil); err != nil {
-		return fmt.Errorf("error starting the dashboard: %v", err)
+// Handler returns the dashboard and every monigo API served under the prefix, ex. "/monigo", protected by the
+// Authorizer and the ControlAuthorizer. It lets the dashboard be mounted on an existing server,
+// ex. mux.Handle("/monigo/", monigoInstance.Handler("/monigo")), instead of opening the dashboard port.
+// Set DisableDashboardServer to skip starting the dashboard server.
+func (m *Monigo) Handler(prefix string) http.Handler {
+	prefix = "/" + strings.Trim(prefix, "/")
+	mux := newDashboardMux(m.authorizers())
+	if prefix == "/" {
+		return mux
 	}
 
-	return nil
+	stripped := http.StripPrefix(prefix, mux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix { // The dashboard uses relative paths, so it must be served with a trailing slash
+			target := prefix + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		stripped.ServeHTTP(w, r)
+	})
+}
+
+// authorizers returns the authorizers of the read-only and the control endpoints, the control ones default to the Authorizer
+func (m *Monigo) authorizers() (read, control Authorizer) {
+	control = m.ControlAuthorizer
+	if control == nil {
+		control = m.Authorizer
+	}
+	return m.Authorizer, control
+}
+
+// dashboardRoute is an endpoint served by the dashboard
+type dashboardRoute struct {
+	Pattern string
+	Handler http.HandlerFunc
+	Access  Access
+}
+
+// dashboardRoutes returns every endpoint served by the dashboard
+func dashboardRoutes() []dashboardRoute {
+	return []dashboardRoute{
+		// HTML site
+		{Pattern: "/", Handler: serveHtmlSite, Access: AccessRead},
+
+		// API to get Service Statistics
+		{Pattern: fmt.Sprintf("%s/metrics", baseAPIPath), Handler: api.GetServiceStatistics, Access: AccessRead},
+
+		// Service APIs
+		{Pattern: fmt.Sprintf("%s/service-info", baseAPIPath), Handler: api.GetServiceInfoAPI, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/service-metrics", baseAPIPath), Handler: api.GetServiceMetricsFromStorage, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/go-routines-stats", baseAPIPath), Handler: api.GetGoRoutinesStats, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/goroutine-leaks", baseAPIPath), Handler: api.GetGoroutineLeaks, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/events", baseAPIPath), Handler: api.GetServiceEvents, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/function", baseAPIPath), Handler: api.GetFunctionTraceDetails, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/function-details", baseAPIPath), Handler: api.ViewFunctionMaetrtics, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/flame-graph", baseAPIPath), Handler: api.GetFlameGraph, Access: AccessRead},
+
+		// Profiles captured on demand
+		{Pattern: fmt.Sprintf("%s/profiles", baseAPIPath), Handler: api.ListProfiles, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/profiles/capture", baseAPIPath), Handler: api.CaptureProfile, Access: AccessControl},
+		{Pattern: fmt.Sprintf("%s/profiles/download", baseAPIPath), Handler: api.DownloadProfile, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/profiles/report", baseAPIPath), Handler: api.GetProfileReport, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/profiles/archive", baseAPIPath), Handler: api.GetProfileArchive, Access: AccessRead},
+		{Pattern: fmt.Sprintf("%s/profiles/diff", baseAPIPath), Handler: api.GetProfileDiff, Access: AccessRead},
+
+		// Reports
+		{Pattern: fmt.Sprintf("%s/reports", baseAPIPath), Handler: api.GetReportData, Access: AccessRead},
+
+		// Prometheus exposition
+		{Pattern: "/metrics", Handler: api.GetPrometheusMetrics, Access: AccessRead},
+	}
+}
+
+// newDashboardMux registers the dashboard handlers on a new ServeMux, protecting the read-only endpoints
+// with the read authorizer and the control endpoints with the control authorizer. Nil authorizers allow every request.
+func newDashboardMux(read, control Authorizer) *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, route := range dashboardRoutes() {
+		authorizer := read
+		if route.Access == AccessControl {
+			authorizer = control
+		}
+		mux.Handle(route.Pattern, requireAuthorization(authorizer, route.Handler))
+	}
+
+	return mux
 }
 
 // serveHtmlSite serves the HTML, CSS, JS, and other static files
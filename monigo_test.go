@@ -0,0 +1,159 @@
+package monigo
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/timeseries"
+)
+
+// chdirTemp runs the test in a temporary working directory, so the files written by monigo are removed afterwards.
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestHandlerAuthorization(t *testing.T) {
+	chdirTemp(t) // The captured profiles are stored under the working directory
+
+	m := &Monigo{
+		Authorizer:        BasicAuth(map[string]string{"admin": "s3cr3t"}),
+		ControlAuthorizer: BearerToken("control-token"),
+	}
+	handler := m.Handler("/monigo")
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		auth   func(r *http.Request)
+		want   int
+	}{
+		{"read without credentials", http.MethodGet, "/monigo/monigo/api/v1/service-info", nil, http.StatusUnauthorized},
+		{"read with wrong password", http.MethodGet, "/monigo/monigo/api/v1/service-info", func(r *http.Request) { r.SetBasicAuth("admin", "wrong") }, http.StatusUnauthorized},
+		{"read with basic auth", http.MethodGet, "/monigo/monigo/api/v1/service-info", func(r *http.Request) { r.SetBasicAuth("admin", "s3cr3t") }, http.StatusOK},
+		{"control without credentials", http.MethodPost, "/monigo/monigo/api/v1/profiles/capture?kind=goroutine", nil, http.StatusUnauthorized},
+		{"control with read credentials", http.MethodPost, "/monigo/monigo/api/v1/profiles/capture?kind=goroutine", func(r *http.Request) { r.SetBasicAuth("admin", "s3cr3t") }, http.StatusUnauthorized},
+		{"control with bearer token", http.MethodPost, "/monigo/monigo/api/v1/profiles/capture?kind=goroutine", func(r *http.Request) { r.Header.Set("Authorization", "Bearer control-token") }, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.auth != nil {
+				tt.auth(r)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerControlDefaultsToAuthorizer(t *testing.T) {
+	handler := (&Monigo{Authorizer: BearerToken("read-token")}).Handler("/")
+
+	r := httptest.NewRequest(http.MethodPost, "/monigo/api/v1/profiles/capture", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+// startMonigo starts monigo and waits until the first data points are stored, the returned function stops it.
+func startMonigo(t *testing.T, m *Monigo) func() {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	stopped := make(chan struct{})
+	go func() {
+		m.Start(ctx)
+		close(stopped)
+	}()
+
+	// Start stores the first data points before it waits to be stopped
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		m.mu.Lock()
+		started := m.done != nil
+		m.mu.Unlock()
+		if started {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("monigo did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return func() {
+		t.Helper()
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer stopCancel()
+		if err := m.Stop(stopCtx); err != nil {
+			t.Fatalf("Stop() error = %v", err)
+		}
+		<-stopped
+	}
+}
+
+func TestStartStopWithMemoryStorageWritesNothing(t *testing.T) {
+	dir := chdirTemp(t)
+
+	storage := timeseries.NewMemoryStorage(16)
+	m := &Monigo{
+		ServiceName:            "memory-test",
+		Storage:                storage,
+		DisableDashboardServer: true,
+	}
+	startMonigo(t, m)()
+
+	if _, err := storage.Select("goroutines", timeseries.DefaultLabels(), 0, math.MaxInt64); err != nil {
+		t.Errorf("Select() error = %v, want the data points stored in memory", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("%s was created in the working directory", entry.Name())
+	}
+}
+
+func TestStartKeepsTimeZone(t *testing.T) {
+	chdirTemp(t)
+
+	m := &Monigo{
+		ServiceName:            "time-zone-test",
+		TimeZone:               "UTC",
+		Storage:                timeseries.NewMemoryStorage(16),
+		DisableDashboardServer: true,
+	}
+	startMonigo(t, m)()
+
+	if got := m.ServiceStartTime.Location().String(); got != "UTC" {
+		t.Errorf("service start time location = %s, want UTC", got)
+	}
+	if got := common.GetServiceStartTime().Location().String(); got != "UTC" {
+		t.Errorf("reported service start time location = %s, want UTC", got)
+	}
+}
@@ -0,0 +1,113 @@
+// Renders the flame graph returned by the monigo/api/v1/flame-graph API with an ECharts custom series,
+// the root at the top and each frame as wide as its share of the samples. The differential flame graph of the
+// monigo/api/v1/profiles/diff API is rendered the same way, each frame red when it grew and blue when it shrank.
+function renderFlameGraph(element, graph) {
+    const differential = graph.root.base_value !== undefined;
+    const formatValue = (value) => {
+        if (graph.unit === 'nanoseconds') {
+            return `${(value / 1e6).toFixed(2)} ms`;
+        }
+        if (graph.unit === 'bytes') {
+            const units = ['B', 'KB', 'MB', 'GB'];
+            let i = 0;
+            while (value >= 1024 && i < units.length - 1) {
+                value /= 1024;
+                i++;
+            }
+            return `${value.toFixed(2)} ${units[i]}`;
+        }
+        return `${value}`;
+    };
+
+    // Color of a frame, derived from its name so a function keeps its color across graphs
+    const frameColor = (name) => {
+        let hash = 0;
+        for (let i = 0; i < name.length; i++) {
+            hash = (hash * 31 + name.charCodeAt(i)) | 0;
+        }
+        return `hsl(${20 + Math.abs(hash) % 40}, ${70 + Math.abs(hash >> 8) % 20}%, ${55 + Math.abs(hash >> 16) % 15}%)`;
+    };
+
+    // Color of a frame of a differential flame graph, the more saturated the larger the change
+    let maxDelta = 1;
+    const findMaxDelta = (node) => {
+        maxDelta = Math.max(maxDelta, Math.abs(node.delta || 0));
+        (node.children || []).forEach(findMaxDelta);
+    };
+    const deltaColor = (delta) => {
+        if (!delta) {
+            return 'hsl(0, 0%, 85%)';
+        }
+        const lightness = 85 - Math.round(Math.abs(delta) / maxDelta * 35);
+        return delta > 0 ? `hsl(0, 80%, ${lightness}%)` : `hsl(215, 80%, ${lightness}%)`;
+    };
+    if (differential) {
+        (graph.root.children || []).forEach(findMaxDelta);
+    }
+
+    const data = [];
+    let depth = 0;
+    const flatten = (node, level, start) => {
+        depth = Math.max(depth, level + 1);
+        data.push({
+            name: node.name,
+            value: [level, start, start + node.value, node.name, node.self, node.value, node.delta || 0],
+            itemStyle: { color: differential ? deltaColor(node.delta) : frameColor(node.name) }
+        });
+        let childStart = start;
+        (node.children || []).forEach(child => {
+            flatten(child, level + 1, childStart);
+            childStart += child.value;
+        });
+    };
+    flatten(graph.root, 0, 0);
+
+    const frameHeight = 22;
+    element.style.height = `${Math.max(depth * frameHeight + 40, 200)}px`;
+
+    const chart = echarts.getInstanceByDom(element) || echarts.init(element);
+    chart.setOption({
+        tooltip: {
+            formatter: (params) => {
+                const [, , , name, self, total, delta] = params.value;
+                const percent = graph.total ? (total / graph.total * 100).toFixed(2) : 0;
+                const change = differential ? `<br/>Change: ${delta < 0 ? '-' : '+'}${formatValue(Math.abs(delta))}` : '';
+                return `${name}<br/>Total: ${formatValue(total)} (${percent}%)<br/>Self: ${formatValue(self)}${change}`;
+            }
+        },
+        grid: { left: 0, right: 0, top: 10, bottom: 10 },
+        xAxis: { show: false, min: 0, max: graph.root.value || 1 },
+        yAxis: { show: false, min: 0, max: depth, inverse: true },
+        series: [{
+            type: 'custom',
+            renderItem: (params, api) => {
+                const level = api.value(0);
+                const start = api.coord([api.value(1), level]);
+                const end = api.coord([api.value(2), level]);
+                const width = end[0] - start[0];
+                const height = api.size([0, 1])[1];
+                return {
+                    type: 'rect',
+                    shape: { x: start[0], y: start[1], width: Math.max(width - 1, 0.5), height: Math.max(height - 2, 1) },
+                    style: { fill: api.visual('color') },
+                    textConfig: { position: 'insideLeft' },
+                    textContent: {
+                        style: {
+                            text: api.value(3),
+                            fill: '#000',
+                            fontSize: 11,
+                            width: Math.max(width - 8, 0),
+                            overflow: 'truncate',
+                            ellipsis: '..',
+                            truncateMinChar: 1
+                        }
+                    }
+                };
+            },
+            encode: { x: [1, 2], y: 0 },
+            data: data
+        }]
+    }, true);
+    chart.resize();
+    return chart;
+}
@@ -18,7 +18,7 @@ document.addEventListener('DOMContentLoaded', () => {
         Object.values(uiElements).forEach(el => el && (el.innerHTML = loadingHtml));
 
         function fetchAndDisplayFunctionMetrics() {
-            fetch(`/monigo/api/v1/function`)
+            fetch(`monigo/api/v1/function`)
                 .then(response => response.json())
                 .then(functionData => {
                     const { functionDetailsContainer, totalFunctionCount } = uiElements;
@@ -30,7 +30,17 @@ document.addEventListener('DOMContentLoaded', () => {
 
                     totalFunctionCount.innerHTML = `<h3><strong>${Object.keys(functionData).length}</strong></h3>`;
                     functionDetailsContainer.innerHTML = Object.keys(functionData).map((funcName) => {
-                        const { function_last_ran_at: lastRanAt } = functionData[funcName];
+                        const {
+                            function_last_ran_at: lastRanAt,
+                            call_count: callCount = 0,
+                            error_count: errorCount = 0,
+                            panic_count: panicCount = 0,
+                            execution_time_ms: executionTime = {},
+                            allocated_bytes: allocatedBytes = {},
+                            parents = {},
+                            error: lastError = '',
+                        } = functionData[funcName];
+                        const calledWithin = Object.entries(parents).map(([parent, calls]) => `${parent} (${calls})`).join(', ');
                         return `
                             <div class="col-lg-4 col-md-4">
                                 <div class="card card-block card-stretch card-height">
@@ -39,7 +49,12 @@ document.addEventListener('DOMContentLoaded', () => {
                                             <div class="style-text text-left">
                                                 <h5 class="mb-2">Function Name:</h5>
                                                 <p class="mb-2">${funcName}</p>
-                                                <p class="mb-0">Last Ran At: ${lastRanAt}</p>
+                                                <p class="mb-2">Last Ran At: ${lastRanAt}</p>
+                                                <p class="mb-1">Calls: ${callCount} | Errors: ${errorCount} | Panics: ${panicCount}</p>
+                                                <p class="mb-1">Execution Time: mean ${executionTime.mean ?? 0} ms, p95 ${executionTime.p95 ?? 0} ms, p99 ${executionTime.p99 ?? 0} ms</p>
+                                                <p class="mb-0">Allocated: mean ${formatBytes(allocatedBytes.mean)}, p95 ${formatBytes(allocatedBytes.p95)}</p>
+                                                ${calledWithin ? `<p class="mb-0">Called Within: ${calledWithin}</p>` : ''}
+                                                ${lastError ? `<p class="mb-0 text-danger">Last Error: ${lastError}</p>` : ''}
                                             </div>
                                             <div class="card-header-toolbar d-flex align-items-center">
                                                 <div><a href="#" class="btn btn-primary view-btn font-size-14" data-func-name="${funcName}">Detailed View</a></div>
@@ -63,6 +78,110 @@ document.addEventListener('DOMContentLoaded', () => {
                 });
         }
 
+        function formatBytes(bytes = 0) {
+            const units = ['B', 'KB', 'MB', 'GB'];
+            let value = bytes;
+            let unit = 0;
+            while (value >= 1024 && unit < units.length - 1) {
+                value /= 1024;
+                unit++;
+            }
+            return `${value.toFixed(unit === 0 ? 0 : 2)} ${units[unit]}`;
+        }
+
+        function renderFunctionTrend(funcName) {
+            const startTime = new Date(new Date().getTime() - 60 * 60000).toISOString(); // Last 1 hour
+            fetch(`monigo/api/v1/function?name=${encodeURIComponent(funcName)}&start_time=${encodeURIComponent(startTime)}`)
+                .then(response => response.json())
+                .then(functionData => {
+                    const trend = functionData[funcName]?.trend || [];
+                    const chartElement = document.getElementById('function-trend-chart');
+                    if (!chartElement) {
+                        return;
+                    }
+                    if (trend.length === 0) {
+                        chartElement.innerHTML = `<p>No stored statistics yet, they are stored at the data points sync frequency.</p>`;
+                        return;
+                    }
+
+                    const series = (metric) => trend.map(point => point.values[metric]);
+                    echarts.init(chartElement).setOption({
+                        tooltip: { trigger: 'axis' },
+                        legend: { data: ['Mean', 'P95', 'P99', 'Calls'], top: 0 },
+                        grid: { left: '3%', right: '4%', bottom: '3%', containLabel: true },
+                        xAxis: { type: 'category', boundaryGap: false, data: trend.map(point => new Date(point.time).toLocaleTimeString()) },
+                        yAxis: [
+                            { type: 'value', axisLabel: { formatter: '{value} ms' } },
+                            { type: 'value', name: 'Calls' }
+                        ],
+                        series: [
+                            { name: 'Mean', type: 'line', data: series('function_execution_time_mean') },
+                            { name: 'P95', type: 'line', data: series('function_execution_time_p95') },
+                            { name: 'P99', type: 'line', data: series('function_execution_time_p99') },
+                            { name: 'Calls', type: 'line', yAxisIndex: 1, data: series('function_calls') }
+                        ]
+                    });
+                })
+                .catch(error => {
+                    console.error('Error fetching function trend:', error);
+                });
+        }
+
+        function escapeHtml(text = '') {
+            return String(text).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+        }
+
+        function formatProfileValue(value = 0, unit = '') {
+            if (unit === 'nanoseconds') {
+                return `${(value / 1e6).toFixed(2)} ms`;
+            }
+            if (unit === 'bytes') {
+                return formatBytes(value);
+            }
+            return `${value}`;
+        }
+
+        function renderProfileReport(report) {
+            if (!report || report.error) {
+                return `<div class="alert alert-warning" role="alert">${escapeHtml(report?.error || 'No profile data available.')}</div>`;
+            }
+            const value = (v) => formatProfileValue(v, report.unit);
+            const header = `<p class="mb-2">Sample type: ${report.sample_type}, total: ${value(report.total)}</p>`;
+
+            if (report.type === 'list') {
+                if (!report.list?.length) {
+                    return header + '<p>The function has no samples in the profile.</p>';
+                }
+                return header + report.list.map(source => `
+                    <p class="mb-1"><strong>${escapeHtml(source.function)}</strong> ${escapeHtml(source.file)} (flat ${value(source.flat)}, cum ${value(source.cum)})</p>
+                    <table class="table table-sm">
+                        <thead><tr><th>Flat</th><th>Cum</th><th>Line</th><th>Source</th></tr></thead>
+                        <tbody>${source.lines.map(line => `
+                            <tr><td>${value(line.flat)}</td><td>${value(line.cum)}</td><td>${line.line}</td><td><pre class="mb-0">${escapeHtml(line.source || '')}</pre></td></tr>
+                        `).join('')}</tbody>
+                    </table>
+                `).join('');
+            }
+
+            const rows = report.type === 'tree' ? report.tree : report.top;
+            if (!rows?.length) {
+                return header + '<p>The profile has no samples.</p>';
+            }
+            const edges = (list = []) => list.map(edge => `${escapeHtml(edge.function)} (${value(edge.value)})`).join('<br>');
+            return header + `
+                <table class="table table-sm">
+                    <thead><tr><th>Flat</th><th>Flat%</th><th>Cum</th><th>Cum%</th><th>Function</th>${report.type === 'tree' ? '<th>Callers</th><th>Callees</th>' : ''}</tr></thead>
+                    <tbody>${rows.map(row => `
+                        <tr>
+                            <td>${value(row.flat)}</td><td>${row.flat_percent}%</td><td>${value(row.cum)}</td><td>${row.cum_percent}%</td>
+                            <td>${escapeHtml(row.function)}</td>
+                            ${report.type === 'tree' ? `<td>${edges(row.callers)}</td><td>${edges(row.callees)}</td>` : ''}
+                        </tr>
+                    `).join('')}</tbody>
+                </table>
+            `;
+        }
+
         function openFunctionDetailModal(funcName) {
             const modalHtml = `
                 <div class="modal fade bd-example-modal-xl" id="functionDetailModal" tabindex="-1" role="dialog" aria-labelledby="functionDetailModalTitle" aria-hidden="true">
@@ -78,11 +197,31 @@ document.addEventListener('DOMContentLoaded', () => {
                                 <div class="form-group">
                                     <label for="reportTypeSelect">Select Type</label>
                                     <div id="reportTypeButtons" class="btn-group ml-3" role="group">
-                                        <button type="button" class="btn btn-outline-primary report-type-btn active" data-bs-toggle="tooltip" title="Prints the location entries, one per line, including the flat and cum values. For more info check 'pprof'" data-report-type="text" id="btn-text">Text<span style="color:red; font-size: 18px; vertical-align: top;">*</span></button>
-                                        <button type="button" class="btn btn-outline-primary report-type-btn" data-bs-toggle="tooltip" title="Prints each location entry with its predecessors and successors. For more info check 'pprof'" data-report-type="traces" id="btn-traces">Traces<span style="color:red; font-size: 18px; vertical-align: top;">*</span></button>
-                                        <button type="button" class="btn btn-outline-primary report-type-btn" data-bs-toggle="tooltip" title="Prints each sample with a location per line. For more info check 'pprof'" data-report-type="tree" id="btn-tree">Tree<span style="color:red; font-size: 18px; vertical-align: top;">*</span></button>
+                                        <button type="button" class="btn btn-outline-primary report-type-btn active" data-bs-toggle="tooltip" title="Functions with the most samples in their own code, including the flat and cum values." data-report-type="top" id="btn-top">Top</button>
+                                        <button type="button" class="btn btn-outline-primary report-type-btn" data-bs-toggle="tooltip" title="Lines of the traced function with their samples, the source is shown when available." data-report-type="list" id="btn-list">List</button>
+                                        <button type="button" class="btn btn-outline-primary report-type-btn" data-bs-toggle="tooltip" title="Functions with the most samples in their call tree along with their callers and callees." data-report-type="tree" id="btn-tree">Tree</button>
                                     </div>
                                 </div>
+                                <h5>Execution Time Trend</h5>
+                                <div id="function-trend-chart" class="chart-container"></div>
+                                <h5>Flame Graph</h5>
+                                <div class="form-inline mb-2">
+                                    <div class="btn-group mr-3" role="group">
+                                        <button type="button" class="btn btn-outline-primary flame-profile-btn active" data-profile="cpu">CPU</button>
+                                        <button type="button" class="btn btn-outline-primary flame-profile-btn" data-profile="heap">Heap</button>
+                                    </div>
+                                    <div class="form-check mr-3">
+                                        <input class="form-check-input" type="checkbox" id="flame-collapse-runtime" checked>
+                                        <label class="form-check-label" for="flame-collapse-runtime">Collapse runtime frames</label>
+                                    </div>
+                                    <div class="form-check mr-3">
+                                        <input class="form-check-input" type="checkbox" id="flame-compare-previous">
+                                        <label class="form-check-label" for="flame-compare-previous">Compare with the previous profiled call</label>
+                                    </div>
+                                    <input type="text" class="form-control form-control-sm" id="flame-focus" placeholder="Function name prefix, ex. main.">
+                                </div>
+                                <div id="function-flame-graph"></div>
+                                <div id="function-flame-diff"></div>
                                 <div id="function-details-content">Loading details...</div>
                             </div>
                             <div class="modal-footer">
@@ -109,16 +248,19 @@ document.addEventListener('DOMContentLoaded', () => {
             initializeTooltips();
 
             const fetchFunctionDetails = (reportType) => {
-                fetch(`/monigo/api/v1/function-details?name=${funcName}&reportType=${reportType}`)
-                    .then(response => response.json())
+                fetch(`monigo/api/v1/function-details?name=${encodeURIComponent(funcName)}&reportType=${reportType}`)
+                    .then(response => {
+                        if (response.status === 400) {
+                            return response.text().then(text => Promise.reject(new Error(text)));
+                        }
+                        return response.json(); // A missing profile is reported within the details
+                    })
                     .then(details => {
                         const content = `
-                            <h5>Code Trace</h5>
-                            <pre>${details?.function_code_trace || 'No code trace data available.'}</pre>
-                            <h5>Core Profile</h5>
-                            <pre>${details?.core_profile?.cpu_profile || 'No core profile data available.'}</pre>
+                            <h5>CPU Profile</h5>
+                            ${renderProfileReport(details?.cpu_profile)}
                             <h5>Memory Profile</h5>
-                            <pre>${details?.core_profile?.mem_profile || 'No memory profile data available.'}</pre>
+                            ${renderProfileReport(details?.mem_profile)}
                         `;
                         document.getElementById('function-details-content').innerHTML = content;
                     })
@@ -131,7 +273,86 @@ document.addEventListener('DOMContentLoaded', () => {
                         `;
                     });
             };
-            fetchFunctionDetails('text');
+            fetchFunctionDetails('top');
+            let flameProfile = 'cpu';
+            // Compares the profile of the last profiled call to the previous one, the graph being that of the difference
+            const fetchProfileDiff = (params) => {
+                return fetch(`monigo/api/v1/function?name=${encodeURIComponent(funcName)}`)
+                    .then(response => response.ok ? response.json() : response.text().then(text => Promise.reject(new Error(text))))
+                    .then(details => {
+                        const profiles = details[funcName]?.profiles || [];
+                        const path = flameProfile === 'cpu' ? 'cpu_profile_file_path' : 'mem_profile_file_path';
+                        if (profiles.length < 2 || !profiles[0][path] || !profiles[1][path]) {
+                            return Promise.reject(new Error('At least two profiled calls are needed to compare.'));
+                        }
+                        params.set('base', profiles[1][path]);
+                        params.set('target', profiles[0][path]);
+                        params.set('limit', 10);
+                        return fetch(`monigo/api/v1/profiles/diff?${params}`);
+                    })
+                    .then(response => response.ok ? response.json() : response.text().then(text => Promise.reject(new Error(text))))
+                    .then(diff => {
+                        const rows = diff.functions.map(row => `
+                            <tr>
+                                <td>${escapeHtml(row.function)}</td>
+                                <td>${formatProfileValue(row.base_flat, diff.unit)}</td><td>${formatProfileValue(row.target_flat, diff.unit)}</td>
+                                <td>${row.flat_delta < 0 ? '-' : '+'}${formatProfileValue(Math.abs(row.flat_delta), diff.unit)} (${row.flat_delta_percent}%)</td>
+                                <td>${row.cum_delta < 0 ? '-' : '+'}${formatProfileValue(Math.abs(row.cum_delta), diff.unit)} (${row.cum_delta_percent}%)</td>
+                            </tr>
+                        `).join('');
+                        document.getElementById('function-flame-diff').innerHTML = rows ? `
+                            <table class="table table-sm">
+                                <thead><tr><th>Function</th><th>Previous Flat</th><th>Last Flat</th><th>Flat Change</th><th>Cum Change</th></tr></thead>
+                                <tbody>${rows}</tbody>
+                            </table>
+                        ` : '<p>The profiles of the two calls are identical.</p>';
+                        return { unit: diff.unit, total: diff.target_total || diff.base_total, root: diff.flame_graph };
+                    });
+            };
+            const fetchFlameGraph = () => {
+                const flameGraphElement = document.getElementById('function-flame-graph');
+                const params = new URLSearchParams({
+                    collapse_runtime: document.getElementById('flame-collapse-runtime').checked,
+                    focus: document.getElementById('flame-focus').value.trim()
+                });
+                document.getElementById('function-flame-diff').innerHTML = '';
+                const graphRequest = document.getElementById('flame-compare-previous').checked
+                    ? fetchProfileDiff(params)
+                    : fetch(`monigo/api/v1/flame-graph?${params}&${new URLSearchParams({ name: funcName, profile: flameProfile })}`)
+                        .then(response => response.ok ? response.json() : response.text().then(text => Promise.reject(new Error(text))));
+                graphRequest
+                    .then(graph => {
+                        if (!graph.total) {
+                            echarts.getInstanceByDom(flameGraphElement)?.dispose();
+                            flameGraphElement.style.height = '';
+                            flameGraphElement.innerHTML = '<p>The profile has no samples.</p>';
+                            return;
+                        }
+                        flameGraphElement.innerHTML = '';
+                        renderFlameGraph(flameGraphElement, graph);
+                    })
+                    .catch(error => {
+                        echarts.getInstanceByDom(flameGraphElement)?.dispose();
+                        flameGraphElement.style.height = '';
+                        flameGraphElement.innerHTML = `<div class="alert alert-warning" role="alert">${escapeHtml(error.message)}</div>`;
+                    });
+            };
+            document.querySelectorAll('.flame-profile-btn').forEach(button => {
+                button.addEventListener('click', (event) => {
+                    flameProfile = event.target.getAttribute('data-profile');
+                    document.querySelectorAll('.flame-profile-btn').forEach(btn => btn.classList.remove('active'));
+                    event.target.classList.add('active');
+                    fetchFlameGraph();
+                });
+            });
+            document.getElementById('flame-collapse-runtime').addEventListener('change', fetchFlameGraph);
+            document.getElementById('flame-compare-previous').addEventListener('change', fetchFlameGraph);
+            document.getElementById('flame-focus').addEventListener('change', fetchFlameGraph);
+
+            setTimeout(() => {
+                renderFunctionTrend(funcName); // Once the modal is shown, the charts need their size
+                fetchFlameGraph();
+            }, 500);
             document.querySelectorAll('.report-type-btn').forEach(button => {
                 button.addEventListener('click', (event) => {
                     const selectedReportType = event.target.getAttribute('data-report-type');
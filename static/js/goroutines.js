@@ -1,10 +1,6 @@
 document.addEventListener('DOMContentLoaded', () => {
     const goRoutinesNumber = document.getElementById('goroutine-count');
 
-    if (goRoutinesNumber) {
-        fetchGoRoutines();
-    }
-
     // Function to get the local ISO string with timezone offset
     function toLocalISOString(date) {
         const tzOffset = -date.getTimezoneOffset(); // in minutes
@@ -43,7 +39,7 @@ document.addEventListener('DOMContentLoaded', () => {
         };
 
 
-        fetch(`/monigo/api/v1/service-metrics`, {
+        fetch(`monigo/api/v1/service-metrics`, {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
@@ -104,56 +100,166 @@ document.addEventListener('DOMContentLoaded', () => {
             });
     }
 
+    const schedLatencyChart = document.getElementById('sched-latency-chart');
+
+    function fetchSchedulerLatency() {
+        const StartTime = new Date(new Date().getTime() - 60 * 60000); // Subtract 1 hour
+        const EndTime = new Date(); // Current time
+
+        let data = {
+            field_name: ["runtime_sched_latency_p50", "runtime_sched_latency_p90", "runtime_sched_latency_p99"],
+            timerange: "1h",
+            start_time: toLocalISOString(StartTime),
+            end_time: toLocalISOString(EndTime)
+        };
+
+        fetch(`monigo/api/v1/service-metrics`, {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json',
+                },
+                body: JSON.stringify(data),
+            }).then(response => response.json())
+            .then(data => {
+                const points = data || [];
+                const time = points.map(entry => new Date(entry.time));
+
+                const schedLatencyChartObj = echarts.init(schedLatencyChart);
+                schedLatencyChartObj.setOption({
+                    title: {
+                        text: 'Scheduler Latency for last 1 hour',
+                        left: 'center'
+                    },
+                    tooltip: {
+                        trigger: 'axis'
+                    },
+                    legend: {
+                        data: ['P50', 'P90', 'P99'],
+                        top: 30
+                    },
+                    grid: {
+                        left: '3%',
+                        right: '4%',
+                        bottom: '3%',
+                        containLabel: true
+                    },
+                    xAxis: {
+                        type: 'category',
+                        boundaryGap: false,
+                        data: time
+                    },
+                    yAxis: {
+                        type: 'value',
+                        axisLabel: {
+                            formatter: '{value} ms'
+                        }
+                    },
+                    series: [
+                        { name: 'P50', type: 'line', data: points.map(entry => entry.value.runtime_sched_latency_p50) },
+                        { name: 'P90', type: 'line', data: points.map(entry => entry.value.runtime_sched_latency_p90) },
+                        { name: 'P99', type: 'line', data: points.map(entry => entry.value.runtime_sched_latency_p99) }
+                    ]
+                });
+            })
+            .catch((error) => {
+                console.error('Error:', error);
+            });
+    }
+
+    function escapeHtml(text = '') {
+        return String(text).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+    }
+
+    const formatFrame = (frame) => `${escapeHtml(frame.function)}\n\t${escapeHtml(frame.file || '')}${frame.line ? `:${frame.line}` : ''}`;
+
+    let stackView = [];
+    const downloadBtn = document.getElementById('download-stack-view');
+    if (downloadBtn) {
+        downloadBtn.addEventListener('click', () => {
+            const blob = new Blob([stackView.join('\n')], {
+                type: 'text/plain'
+            });
+            const url = URL.createObjectURL(blob);
+            const a = document.createElement('a');
+            a.href = url;
+            a.download = 'go-routines-stack-view.txt';
+            a.click();
+            URL.revokeObjectURL(url);
+        });
+    }
+
+    const filterForm = document.getElementById('goroutine-filter-form');
+    if (filterForm) {
+        filterForm.addEventListener('submit', (event) => {
+            event.preventDefault();
+            fetchGoRoutines();
+        });
+    }
+
+    let chartsLoaded = false;
+
     function fetchGoRoutines() {
-        fetch(`/monigo/api/v1/go-routines-stats`)
-            .then(response => response.json())
+        const params = new URLSearchParams();
+        const filters = {
+            state: document.getElementById('goroutine-state')?.value,
+            function: document.getElementById('goroutine-function')?.value.trim(),
+            min_wait: document.getElementById('goroutine-min-wait')?.value.trim()
+        };
+        Object.entries(filters).forEach(([name, value]) => {
+            if (value) {
+                params.set(name, value);
+            }
+        });
+
+        fetch(`monigo/api/v1/go-routines-stats?${params}`)
+            .then(response => response.ok ? response.json() : response.text().then(text => Promise.reject(new Error(text))))
             .then(data => {
                 goRoutinesNumber.innerHTML = data.number_of_goroutines;
                 const container = document.getElementById('goroutines-container');
-                const countElement = document.getElementById('goroutine-count');
-
-                let goroutines = [];
-                data.stack_view.forEach((item, index) => {
-                    const goroutine = {
-                        id: index + 1,
-                        stackTrace: item
-                    };
-                    goroutines.push(goroutine);
-                });
 
-                fetchDataPointsFromServer();
-
-
-                if (goroutines.length > 0) {
-                    const downloadBtn = document.getElementById('download-stack-view');
-                    if (downloadBtn) {
-                        downloadBtn.style.display = 'block';
-                        downloadBtn.addEventListener('click', () => {
-                            const blob = new Blob([goroutines.map(g => g.stackTrace).join('\n')], {
-                                type: 'text/plain'
-                            });
-                            const url = URL.createObjectURL(blob);
-                            const a = document.createElement('a');
-                            a.href = url;
-                            a.download = 'go-routines-stack-view.txt';
-                            a.click();
-                            URL.revokeObjectURL(url);
-                        });
-                    } else {
-                        downloadBtn.style.display = 'none';
+                if (!chartsLoaded) {
+                    chartsLoaded = true;
+                    fetchDataPointsFromServer();
+                    if (schedLatencyChart) {
+                        fetchSchedulerLatency();
                     }
                 }
 
-                countElement.textContent = goroutines.length;
+                stackView = data.stack_view || [];
+                if (downloadBtn) {
+                    downloadBtn.style.display = stackView.length > 0 ? 'block' : 'none';
+                }
+
+                // State breakdown of every goroutine, also offered as a filter
+                const states = Object.entries(data.states || {}).sort((a, b) => b[1] - a[1]);
+                const stateSelect = document.getElementById('goroutine-state');
+                if (stateSelect) {
+                    const selected = stateSelect.value;
+                    stateSelect.innerHTML = '<option value="">All states</option>' + states.map(([state]) =>
+                        `<option value="${escapeHtml(state)}" ${state === selected ? 'selected' : ''}>${escapeHtml(state)}</option>`).join('');
+                }
+                const statesElement = document.getElementById('goroutine-states');
+                if (statesElement) {
+                    statesElement.innerHTML = states.map(([state, count]) =>
+                        `<span class="badge badge-light mr-2">${escapeHtml(state)}: ${count}</span>`).join('');
+                }
+                const matchedElement = document.getElementById('goroutine-matched');
+                if (matchedElement) {
+                    matchedElement.textContent = `${data.matched_goroutines} goroutines in ${(data.groups || []).length} groups`;
+                }
+
                 container.innerHTML = '';
 
-                // Iterate over each goroutine and create HTML content
-                goroutines.forEach(goroutine => {
+                // Iterate over each group of identical stacks and create HTML content
+                (data.groups || []).forEach(group => {
+                    const groupStates = Object.entries(group.states).map(([state, count]) => `${escapeHtml(state)} (${count})`).join(', ');
+                    const wait = group.max_wait_minutes ? `, waiting ${group.min_wait_minutes}-${group.max_wait_minutes} minutes` : '';
+                    const createdBy = group.created_by ? `\ncreated by ${formatFrame(group.created_by)}` : '';
                     const div = document.createElement('div');
                     div.className = 'goroutine';
                     div.innerHTML = `
-                        <div class="goroutine-header">Goroutine ${goroutine.id}:</div>
-                        <pre>${goroutine.stackTrace}</pre>
+                        <div class="goroutine-header">${group.count} goroutine${group.count > 1 ? 's' : ''}: ${groupStates}${wait}</div>
+                        <pre>${group.frames.map(formatFrame).join('\n')}${createdBy}</pre>
                     `;
                     container.appendChild(div);
                 });
@@ -162,4 +268,46 @@ document.addEventListener('DOMContentLoaded', () => {
                 console.error(error);
             });
     }
-});
\ No newline at end of file
+
+    function fetchGoroutineLeaks() {
+        const summary = document.getElementById('goroutine-leaks-summary');
+        const container = document.getElementById('goroutine-leaks-container');
+        if (!summary || !container) {
+            return;
+        }
+
+        fetch(`monigo/api/v1/goroutine-leaks`)
+            .then(response => response.ok ? response.json() : response.text().then(text => Promise.reject(new Error(text))))
+            .then(data => {
+                container.innerHTML = '';
+                if (!data.enabled) {
+                    summary.textContent = 'Goroutine leak detection is disabled.';
+                    return;
+                }
+                if (data.suspects.length === 0) {
+                    summary.textContent = `No leak suspected over ${data.snapshots} snapshots.`;
+                    return;
+                }
+                summary.textContent = `${data.suspects.length} creation site${data.suspects.length > 1 ? 's' : ''} suspected over ${data.snapshots} snapshots, health lowered by ${data.health_penalty}%.`;
+
+                data.suspects.forEach(site => {
+                    const history = site.history.map(point => point.count).join(' → ');
+                    const div = document.createElement('div');
+                    div.className = 'goroutine';
+                    div.innerHTML = `
+                        <div class="goroutine-header">${site.count} goroutine${site.count > 1 ? 's' : ''} created by ${escapeHtml(site.created_by.function)}: ${site.reasons.map(escapeHtml).join(', ')}</div>
+                        <div class="mb-2">Count: ${history}</div>
+                        <pre>${site.frames.map(formatFrame).join('\n')}\ncreated by ${formatFrame(site.created_by)}</pre>
+                    `;
+                    container.appendChild(div);
+                });
+            }).catch(error => {
+                console.error(error);
+            });
+    }
+
+    if (goRoutinesNumber) {
+        fetchGoRoutines();
+        fetchGoroutineLeaks();
+    }
+});
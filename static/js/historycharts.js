@@ -84,7 +84,7 @@ document.addEventListener('DOMContentLoaded', () => {
         };
         
 
-        fetch(`/monigo/api/v1/service-metrics`, {
+        fetch(`monigo/api/v1/service-metrics`, {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
@@ -48,7 +48,7 @@ document.addEventListener('DOMContentLoaded', () => {
     }
 
     function fetchServiceInfo() {
-        fetch(`/monigo/api/v1/service-info`)
+        fetch(`monigo/api/v1/service-info`)
             .then(response => response.json())
             .then(data => {
                 service_name.innerHTML = '';
@@ -189,7 +189,7 @@ document.addEventListener('DOMContentLoaded', () => {
     }
 
     function fetchMetrics() {
-        fetch(`/monigo/api/v1/metrics`)
+        fetch(`monigo/api/v1/metrics`)
             .then(response => response.json())
             .then(data => {
                 const {
@@ -523,6 +523,10 @@ document.addEventListener('DOMContentLoaded', () => {
             metricList = ["pause_total_ns", "num_gc", "gc_cpu_fraction"];
         } else if (metricName == "misc") {
             metricList = ["m_span_inuse", "m_span_sys", "m_cache_inuse", "m_cache_sys", "buck_hash_sys", "gc_sys", "other_sys"];
+        } else if (metricName == "gc-pauses") {
+            metricList = ["runtime_gc_pause_p50", "runtime_gc_pause_p90", "runtime_gc_pause_p99"];
+        } else if (metricName == "heap-goal") {
+            metricList = ["runtime_heap_live", "runtime_heap_goal", "runtime_memory_limit"];
         }
 
         let data = {
@@ -532,7 +536,7 @@ document.addEventListener('DOMContentLoaded', () => {
             end_time: toLocalISOString(EndTime)
         };
 
-        fetch(`/monigo/api/v1/service-metrics`, {
+        fetch(`monigo/api/v1/service-metrics`, {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
@@ -619,6 +623,10 @@ document.addEventListener('DOMContentLoaded', () => {
                 return 'Garbage Collection Over Time';
             case 'misc':
                 return 'Miscellaneous System Memory Over Time';
+            case 'gc-pauses':
+                return 'GC Pause Latency Over Time';
+            case 'heap-goal':
+                return 'Heap Goal and Memory Limit Over Time';
             default:
                 return '';
         }
@@ -632,6 +640,10 @@ document.addEventListener('DOMContentLoaded', () => {
             return `${value} KB`;
         } else if (metric === 'gc' && value <= 1) {
             return value.toFixed(4);
+        } else if (metric === 'gc-pauses') {
+            return `${value} ms`;
+        } else if (metric === 'heap-goal') {
+            return `${(value / 1024 / 1024).toFixed(1)} MB`;
         } else {
             return value;
         }
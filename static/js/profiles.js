@@ -0,0 +1,148 @@
+document.addEventListener('DOMContentLoaded', () => {
+    const profileList = document.getElementById('profile-list');
+    if (!profileList) {
+        return;
+    }
+
+    const captureForm = document.getElementById('profile-capture-form');
+    const captureStatus = document.getElementById('profile-capture-status');
+    const captureButton = document.getElementById('profile-capture-btn');
+    const profileView = document.getElementById('profile-view');
+    const flameGraphElement = document.getElementById('profile-flame-graph');
+    const archiveList = document.getElementById('profile-archive-list');
+
+    function escapeHtml(text = '') {
+        return String(text).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+    }
+
+    function formatSize(bytes = 0) {
+        const units = ['B', 'KB', 'MB', 'GB'];
+        let i = 0;
+        while (bytes >= 1024 && i < units.length - 1) {
+            bytes /= 1024;
+            i++;
+        }
+        return `${bytes.toFixed(1)} ${units[i]}`;
+    }
+
+    function formatValue(value, unit) {
+        if (unit === 'nanoseconds') {
+            return `${(value / 1e6).toFixed(2)} ms`;
+        }
+        if (unit === 'bytes') {
+            return formatSize(value);
+        }
+        return `${value}`;
+    }
+
+    function renderProfiles(element, profiles, emptyMessage) {
+        if (!profiles.length) {
+            element.innerHTML = `<tr><td colspan="5">${emptyMessage}</td></tr>`;
+            return;
+        }
+        element.innerHTML = profiles.map(profile => `
+            <tr>
+                <td>${new Date(profile.captured_at).toLocaleString()}</td>
+                <td>${profile.kind}</td>
+                <td>${profile.duration_seconds ? `${profile.duration_seconds} s` : 'Snapshot'}</td>
+                <td>${formatSize(profile.size_bytes)}</td>
+                <td class="text-right">
+                    ${profile.kind !== 'trace' ? `<button type="button" class="btn btn-sm btn-outline-primary profile-view-btn" data-id="${profile.id}">View</button>` : ''}
+                    <a class="btn btn-sm btn-outline-secondary" href="monigo/api/v1/profiles/download?id=${profile.id}">Download</a>
+                </td>
+            </tr>
+        `).join('');
+        element.querySelectorAll('.profile-view-btn').forEach(button => {
+            button.addEventListener('click', () => viewProfile(button.getAttribute('data-id')));
+        });
+    }
+
+    function fetchProfiles() {
+        fetch(`monigo/api/v1/profiles`)
+            .then(response => response.json())
+            .then(profiles => renderProfiles(profileList, profiles, 'No profile captured yet.'))
+            .catch(error => {
+                console.error('Error fetching profiles:', error);
+            });
+    }
+
+    // Lists the latest archived profiles, or the profiles captured the closest to the time when one is given
+    function fetchArchive(at) {
+        const query = at ? `at=${encodeURIComponent(new Date(at).toISOString())}` : '';
+        fetch(`monigo/api/v1/profiles/archive?${query}`)
+            .then(response => response.ok ? response.json() : response.text().then(text => Promise.reject(new Error(text))))
+            .then(profiles => renderProfiles(archiveList, at ? profiles : profiles.slice(0, 20), 'No archived profile, set ContinuousProfiling to archive profiles.'))
+            .catch(error => {
+                archiveList.innerHTML = `<tr><td colspan="5">${escapeHtml(error.message)}</td></tr>`;
+            });
+    }
+
+    function viewProfile(id) {
+        fetch(`monigo/api/v1/profiles/report?id=${id}&reportType=top&limit=20`)
+            .then(response => response.ok ? response.json() : response.text().then(text => Promise.reject(new Error(text))))
+            .then(report => {
+                const rows = (report.top || []).map(row => `
+                    <tr><td>${formatValue(row.flat, report.unit)}</td><td>${row.flat_percent}%</td><td>${formatValue(row.cum, report.unit)}</td><td>${row.cum_percent}%</td><td>${escapeHtml(row.function)}</td></tr>
+                `).join('');
+                profileView.innerHTML = `
+                    <h5>${id}</h5>
+                    <p class="mb-2">Sample type: ${report.sample_type}, total: ${formatValue(report.total, report.unit)}</p>
+                    ${rows ? `<table class="table table-sm"><thead><tr><th>Flat</th><th>Flat%</th><th>Cum</th><th>Cum%</th><th>Function</th></tr></thead><tbody>${rows}</tbody></table>` : '<p>The profile has no samples.</p>'}
+                `;
+            })
+            .catch(error => {
+                profileView.innerHTML = `<div class="alert alert-warning" role="alert">${escapeHtml(error.message)}</div>`;
+            });
+
+        fetch(`monigo/api/v1/flame-graph?id=${id}&collapse_runtime=true`)
+            .then(response => response.ok ? response.json() : Promise.reject(new Error(response.statusText)))
+            .then(graph => {
+                if (!graph.total) {
+                    echarts.getInstanceByDom(flameGraphElement)?.dispose();
+                    flameGraphElement.style.height = '';
+                    return;
+                }
+                renderFlameGraph(flameGraphElement, graph);
+            })
+            .catch(error => {
+                console.error('Error fetching the flame graph:', error);
+            });
+    }
+
+    captureForm.addEventListener('submit', (event) => {
+        event.preventDefault();
+        const params = new URLSearchParams({ kind: document.getElementById('profile-kind').value });
+        const seconds = document.getElementById('profile-seconds').value;
+        const rate = document.getElementById('profile-rate').value;
+        if (seconds) {
+            params.set('seconds', seconds);
+        }
+        if (rate) {
+            params.set('rate', rate);
+        }
+
+        captureButton.disabled = true;
+        captureStatus.textContent = 'Capturing...';
+        fetch(`monigo/api/v1/profiles/capture?${params}`, { method: 'POST' })
+            .then(response => response.ok ? response.json() : response.text().then(text => Promise.reject(new Error(text))))
+            .then(profile => {
+                captureStatus.textContent = `Captured ${profile.id}`;
+                fetchProfiles();
+            })
+            .catch(error => {
+                captureStatus.textContent = error.message;
+            })
+            .finally(() => {
+                captureButton.disabled = false;
+            });
+    });
+
+    document.getElementById('profile-archive-form').addEventListener('submit', (event) => {
+        event.preventDefault();
+        fetchArchive(document.getElementById('profile-archive-at').value);
+    });
+    document.getElementById('profile-archive-latest').addEventListener('click', () => fetchArchive());
+
+    fetchProfiles();
+    fetchArchive();
+});
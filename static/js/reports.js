@@ -65,7 +65,7 @@ document.addEventListener('DOMContentLoaded', () => {
             time_frame: timeframe
         };
 
-        fetch('/monigo/api/v1/reports', {
+        fetch('monigo/api/v1/reports', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json'
@@ -0,0 +1,129 @@
+package timeseries
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iyashjayesh/monigo/common"
+	"github.com/iyashjayesh/monigo/models"
+)
+
+const (
+	ServiceStartEvent   = "service_start"
+	ServiceRestartEvent = "service_restart"
+	ServiceStopEvent    = "service_stop"
+
+	eventsFile = "events.jsonl" // Events log under the base path, one JSON event per line
+)
+
+var eventsMu sync.Mutex // Guards the events log
+
+// RecordEvent appends the event to the events log, dropping the events older than the data retention period.
+func RecordEvent(event models.ServiceEvent) error {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	events, err := readEvents()
+	if err != nil {
+		return err
+	}
+
+	oldest := time.Now().Add(-common.GetDataRetentionPeriod())
+	retained := make([]models.ServiceEvent, 0, len(events)+1)
+	for _, e := range events {
+		if !e.Time.Before(oldest) {
+			retained = append(retained, e)
+		}
+	}
+	retained = append(retained, event)
+
+	return writeEvents(retained)
+}
+
+// GetEvents returns the events recorded between the start and end time, sorted by time.
+// A zero start or end time leaves the range open on that side.
+func GetEvents(start, end time.Time) ([]models.ServiceEvent, error) {
+	eventsMu.Lock()
+	events, err := readEvents()
+	eventsMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []models.ServiceEvent
+	for _, e := range events {
+		if (!start.IsZero() && e.Time.Before(start)) || (!end.IsZero() && e.Time.After(end)) {
+			continue
+		}
+		result = append(result, e)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Time.Before(result[j].Time) })
+	return result, nil
+}
+
+// readEvents reads every event of the events log, a missing log has no events.
+func readEvents() ([]models.ServiceEvent, error) {
+	file, err := os.Open(filepath.Join(common.GetBasePath(), eventsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error opening events log: %w", err)
+	}
+	defer file.Close()
+
+	var events []models.ServiceEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e models.ServiceEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // Skipping a line left incomplete by a crash
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading events log: %w", err)
+	}
+	return events, nil
+}
+
+// writeEvents replaces the events log with the events, writing to a temporary file first so a crash keeps the previous log.
+func writeEvents(events []models.ServiceEvent) error {
+	if err := os.MkdirAll(common.GetBasePath(), os.ModePerm); err != nil {
+		return fmt.Errorf("error creating events log: %w", err)
+	}
+	path := filepath.Join(common.GetBasePath(), eventsFile)
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("error creating events log: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			file.Close()
+			return fmt.Errorf("error writing events log: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing events log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing events log: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("error replacing events log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package timeseries
+
+import (
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/nakabonne/tstorage"
+)
+
+var (
+	labelsMu       sync.RWMutex
+	defaultLabels  []tstorage.Label // Labels every series of the service is stored with, set by SetDefaultLabels
+	instanceLabels []tstorage.Label // Labels describing the running instance, not stored, set by SetInstanceLabels
+)
+
+// SetDefaultLabels sets the labels every series of the service is stored with, ex. host and service.
+// The storage identifies a series by its exact labels, so they must stay the same across deploys and restarts
+// for the earlier data to be queried. Labels with an empty name or value are dropped as the storage ignores them.
+func SetDefaultLabels(labels map[string]string) {
+	l := toLabels(labels)
+
+	labelsMu.Lock()
+	defer labelsMu.Unlock()
+	defaultLabels = l
+}
+
+// SetInstanceLabels sets the labels describing the running instance, ex. instance_id and version. They are reported
+// by the service info, the lifecycle events and the Prometheus metrics, but not stored, as they change on every deploy.
+func SetInstanceLabels(labels map[string]string) {
+	l := toLabels(labels)
+
+	labelsMu.Lock()
+	defer labelsMu.Unlock()
+	instanceLabels = l
+}
+
+// toLabels returns the labels sorted by name, dropping the labels with an empty name or value.
+func toLabels(labels map[string]string) []tstorage.Label {
+	var l []tstorage.Label
+	for name, value := range labels {
+		if name == "" || value == "" {
+			continue
+		}
+		l = append(l, tstorage.Label{Name: name, Value: value})
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	return l
+}
+
+// DefaultLabels returns a copy of the labels every series of the service is stored with.
+// The host label is used until SetDefaultLabels is called.
+func DefaultLabels() []tstorage.Label {
+	labelsMu.RLock()
+	defer labelsMu.RUnlock()
+
+	if defaultLabels == nil {
+		hostname, _ := os.Hostname()
+		return []tstorage.Label{{Name: "host", Value: hostname}}
+	}
+	return append([]tstorage.Label{}, defaultLabels...)
+}
+
+// InstanceLabels returns a copy of the labels describing the running instance.
+func InstanceLabels() []tstorage.Label {
+	labelsMu.RLock()
+	defer labelsMu.RUnlock()
+
+	return append([]tstorage.Label{}, instanceLabels...)
+}
+
+// DefaultLabelsMap returns the labels of the running instance as a map of name to value,
+// the labels every series is stored with along with the instance labels.
+func DefaultLabelsMap() map[string]string {
+	labels := make(map[string]string)
+	for _, label := range InstanceLabels() {
+		labels[label.Name] = label.Value
+	}
+	for _, label := range DefaultLabels() {
+		labels[label.Name] = label.Value
+	}
+	return labels
+}
+
+// MatchLabels returns the labels of the series to query, the matchers replace the default labels with the same name
+// and add the others. A matcher with an empty value removes the default label. The storage only returns the series
+// with exactly the same labels, so the series of another host are selected by matching its labels.
+func MatchLabels(matchers map[string]string) []tstorage.Label {
+	var labels []tstorage.Label
+	for _, label := range DefaultLabels() {
+		if _, ok := matchers[label.Name]; !ok {
+			labels = append(labels, label)
+		}
+	}
+	for name, value := range matchers {
+		if name != "" && value != "" {
+			labels = append(labels, tstorage.Label{Name: name, Value: value})
+		}
+	}
+	return labels
+}
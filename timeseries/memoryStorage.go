@@ -0,0 +1,144 @@
+package timeseries
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nakabonne/tstorage"
+)
+
+const (
+	defaultMemoryStorageCapacity = 4096 // Default number of data points kept per series
+	minRingBufferCapacity        = 16   // Data points allocated when a series is created, the buffer grows up to the capacity
+)
+
+// MemoryStorage is an in-memory Storage that keeps the most recent data points of every series in a ring buffer.
+// It is meant for tests and short-lived jobs, nothing is written to disk.
+type MemoryStorage struct {
+	capacity int
+	series   map[string]*ringBuffer
+	closed   bool
+	mu       sync.RWMutex
+}
+
+// ringBuffer holds the data points of a single series, oldest first.
+// The points grow with the series until the capacity, as most label combinations only see a few data points.
+type ringBuffer struct {
+	points   []tstorage.DataPoint
+	start    int // Index of the oldest data point once the buffer is full
+	capacity int
+}
+
+// NewMemoryStorage creates a MemoryStorage keeping up to capacity data points per series.
+// A capacity less than 1 falls back to the default of 4096 data points.
+func NewMemoryStorage(capacity int) *MemoryStorage {
+	if capacity < 1 {
+		capacity = defaultMemoryStorageCapacity
+	}
+	return &MemoryStorage{
+		capacity: capacity,
+		series:   make(map[string]*ringBuffer),
+	}
+}
+
+// InsertRows inserts rows into the storage, dropping the oldest data points of a series once it is full.
+func (s *MemoryStorage) InsertRows(rows []tstorage.Row) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errors.New("memory storage is closed")
+	}
+
+	for _, row := range rows {
+		if row.Metric == "" {
+			return errors.New("metric must be set")
+		}
+
+		key := seriesKey(row.Metric, row.Labels)
+		buf, ok := s.series[key]
+		if !ok {
+			buf = &ringBuffer{capacity: s.capacity}
+			s.series[key] = buf
+		}
+		buf.insert(row.DataPoint)
+	}
+	return nil
+}
+
+// Select retrieves the data points of the series in the range [start, end).
+func (s *MemoryStorage) Select(metric string, labels []tstorage.Label, start, end int64) ([]*tstorage.DataPoint, error) {
+	if metric == "" {
+		return nil, errors.New("metric must be set")
+	}
+	if start >= end {
+		return nil, fmt.Errorf("the given start is greater than end")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	buf, ok := s.series[seriesKey(metric, labels)]
+	if !ok {
+		return nil, tstorage.ErrNoDataPoints
+	}
+
+	points := make([]*tstorage.DataPoint, 0)
+	for i := 0; i < len(buf.points); i++ {
+		dp := buf.points[(buf.start+i)%len(buf.points)]
+		if dp.Timestamp >= start && dp.Timestamp < end {
+			points = append(points, &dp)
+		}
+	}
+
+	if len(points) == 0 {
+		return nil, tstorage.ErrNoDataPoints
+	}
+
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Timestamp < points[j].Timestamp })
+	return points, nil
+}
+
+// Close releases the data held by the storage.
+func (s *MemoryStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.series = make(map[string]*ringBuffer)
+	return nil
+}
+
+// insert appends the data point, overwriting the oldest one once the buffer is full.
+func (b *ringBuffer) insert(dp tstorage.DataPoint) {
+	if len(b.points) < b.capacity {
+		if len(b.points) == cap(b.points) { // Doubling the buffer without going over the capacity
+			grown := make([]tstorage.DataPoint, len(b.points), min(max(2*cap(b.points), minRingBufferCapacity), b.capacity))
+			copy(grown, b.points)
+			b.points = grown
+		}
+		b.points = append(b.points, dp)
+		return
+	}
+	b.points[b.start] = dp
+	b.start = (b.start + 1) % len(b.points)
+}
+
+// seriesKey builds a unique key for the metric and labels, independent of the order of the labels.
+// The metric, names and values are quoted, so a value containing "," or "=" cannot match another set of labels.
+func seriesKey(metric string, labels []tstorage.Label) string {
+	pairs := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label.Name == "" || label.Value == "" {
+			continue // Labels with missing name or value are ignored, the same as tstorage
+		}
+		pairs = append(pairs, strconv.Quote(label.Name)+"="+strconv.Quote(label.Value))
+	}
+	sort.Strings(pairs)
+
+	return strconv.Quote(metric) + "{" + strings.Join(pairs, ",") + "}"
+}
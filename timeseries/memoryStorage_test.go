@@ -0,0 +1,193 @@
+package timeseries
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/nakabonne/tstorage"
+)
+
+// insertPoints inserts a data point of the series at every timestamp, valued as the timestamp.
+func insertPoints(t *testing.T, s *MemoryStorage, metric string, labels []tstorage.Label, timestamps ...int64) {
+	t.Helper()
+	rows := make([]tstorage.Row, 0, len(timestamps))
+	for _, ts := range timestamps {
+		rows = append(rows, tstorage.Row{Metric: metric, Labels: labels, DataPoint: tstorage.DataPoint{Timestamp: ts, Value: float64(ts)}})
+	}
+	if err := s.InsertRows(rows); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sequence returns the timestamps from first to last.
+func sequence(first, last int64) []int64 {
+	result := make([]int64, 0, last-first+1)
+	for ts := first; ts <= last; ts++ {
+		result = append(result, ts)
+	}
+	return result
+}
+
+// timestamps returns the timestamps of the data points.
+func timestamps(points []*tstorage.DataPoint) []int64 {
+	result := make([]int64, 0, len(points))
+	for _, dp := range points {
+		result = append(result, dp.Timestamp)
+	}
+	return result
+}
+
+func TestMemoryStorageWrapAround(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		inserted []int64
+		want     []int64
+	}{
+		{name: "below capacity", capacity: 4, inserted: []int64{1, 2, 3}, want: []int64{1, 2, 3}},
+		{name: "at capacity", capacity: 4, inserted: []int64{1, 2, 3, 4}, want: []int64{1, 2, 3, 4}},
+		{name: "wrapped once", capacity: 4, inserted: []int64{1, 2, 3, 4, 5, 6}, want: []int64{3, 4, 5, 6}},
+		{name: "wrapped several times", capacity: 3, inserted: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, want: []int64{8, 9, 10}},
+		{name: "out of order", capacity: 3, inserted: []int64{5, 1, 4, 2}, want: []int64{1, 2, 4}},
+		{name: "default capacity", capacity: 0, inserted: []int64{1, 2}, want: []int64{1, 2}},
+		{name: "grown then wrapped", capacity: 20, inserted: sequence(1, 25), want: sequence(6, 25)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStorage(tt.capacity)
+			insertPoints(t, s, "goroutines", nil, tt.inserted...)
+
+			points, err := s.Select("goroutines", nil, 0, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := timestamps(points); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStorageGrowsLazily(t *testing.T) {
+	s := NewMemoryStorage(0)
+	labels := []tstorage.Label{{Name: "route", Value: "/users/{id}"}}
+	buffer := func() []tstorage.DataPoint { return s.series[seriesKey("http_requests", labels)].points }
+
+	insertPoints(t, s, "http_requests", labels, 1)
+	if got := cap(buffer()); got != minRingBufferCapacity {
+		t.Errorf("capacity of a new series = %d, want %d", got, minRingBufferCapacity)
+	}
+
+	insertPoints(t, s, "http_requests", labels, sequence(2, defaultMemoryStorageCapacity+10)...)
+	if got := cap(buffer()); got != defaultMemoryStorageCapacity {
+		t.Errorf("capacity of a full series = %d, want %d", got, defaultMemoryStorageCapacity)
+	}
+	points, err := s.Select("http_requests", labels, 0, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := timestamps(points); !reflect.DeepEqual(got, sequence(11, defaultMemoryStorageCapacity+10)) {
+		t.Errorf("Select() = %d points from %d to %d, want the last %d", len(got), got[0], got[len(got)-1], defaultMemoryStorageCapacity)
+	}
+}
+
+func TestMemoryStorageSelectRange(t *testing.T) {
+	s := NewMemoryStorage(10)
+	insertPoints(t, s, "goroutines", nil, 10, 20, 30, 40)
+
+	tests := []struct {
+		name       string
+		start, end int64
+		want       []int64
+		wantErr    error
+	}{
+		{name: "every point", start: 0, end: 100, want: []int64{10, 20, 30, 40}},
+		{name: "start included and end excluded", start: 20, end: 40, want: []int64{20, 30}},
+		{name: "single point", start: 30, end: 31, want: []int64{30}},
+		{name: "no point in range", start: 41, end: 100, wantErr: tstorage.ErrNoDataPoints},
+		{name: "empty range", start: 20, end: 20, wantErr: errors.New("the given start is greater than end")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := s.Select("goroutines", nil, tt.start, tt.end)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("Select() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := timestamps(points); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStorageLabelMatching(t *testing.T) {
+	s := NewMemoryStorage(10)
+	hostA := []tstorage.Label{{Name: "host", Value: "a"}, {Name: "service", Value: "api"}}
+	hostB := []tstorage.Label{{Name: "host", Value: "b"}, {Name: "service", Value: "api"}}
+	insertPoints(t, s, "goroutines", hostA, 1)
+	insertPoints(t, s, "goroutines", hostB, 2)
+	insertPoints(t, s, "heap_alloc", hostA, 3)
+	insertPoints(t, s, "http_requests", []tstorage.Label{{Name: "route", Value: "/a,status=2xx"}}, 4)
+	insertPoints(t, s, "http_requests", []tstorage.Label{{Name: "status", Value: "2xx"}, {Name: "route", Value: "/a"}}, 5)
+
+	tests := []struct {
+		name   string
+		metric string
+		labels []tstorage.Label
+		want   []int64
+	}{
+		{name: "exact labels", metric: "goroutines", labels: hostA, want: []int64{1}},
+		{name: "other host", metric: "goroutines", labels: hostB, want: []int64{2}},
+		{name: "labels in another order", metric: "goroutines", labels: []tstorage.Label{{Name: "service", Value: "api"}, {Name: "host", Value: "b"}}, want: []int64{2}},
+		{name: "empty labels ignored", metric: "goroutines", labels: append([]tstorage.Label{{Name: "version", Value: ""}}, hostA...), want: []int64{1}},
+		{name: "other metric", metric: "heap_alloc", labels: hostA, want: []int64{3}},
+		{name: "separators in a value", metric: "http_requests", labels: []tstorage.Label{{Name: "route", Value: "/a,status=2xx"}}, want: []int64{4}},
+		{name: "labels made of the separated value", metric: "http_requests", labels: []tstorage.Label{{Name: "route", Value: "/a"}, {Name: "status", Value: "2xx"}}, want: []int64{5}},
+		{name: "subset of the labels", metric: "goroutines", labels: []tstorage.Label{{Name: "host", Value: "a"}}},
+		{name: "superset of the labels", metric: "goroutines", labels: append([]tstorage.Label{{Name: "region", Value: "eu"}}, hostA...)},
+		{name: "unknown metric", metric: "cpu", labels: hostA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := s.Select(tt.metric, tt.labels, 0, 100)
+			if tt.want == nil {
+				if !errors.Is(err, tstorage.ErrNoDataPoints) {
+					t.Fatalf("Select() = %v, %v, want no data points", timestamps(points), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := timestamps(points); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStorageClose(t *testing.T) {
+	s := NewMemoryStorage(10)
+	insertPoints(t, s, "goroutines", nil, 1)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Select("goroutines", nil, 0, 100); !errors.Is(err, tstorage.ErrNoDataPoints) {
+		t.Errorf("Select() after Close() error = %v, want no data points", err)
+	}
+	if err := s.InsertRows([]tstorage.Row{{Metric: "goroutines"}}); err == nil {
+		t.Error("InsertRows() after Close() succeeded, want an error")
+	}
+}
@@ -3,6 +3,7 @@ package timeseries
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"sync"
@@ -14,12 +15,12 @@ import (
 )
 
 var (
-	once      sync.Once          // Ensures that the storage is initialized only once
-	basePath  string             // Base path for storage
-	storage   Storage            // Storage instance
-	closeOnce sync.Once          // Ensures that the storage is closed only once
-	ctx       context.Context    // Context for goroutines
-	cancel    context.CancelFunc // Cancel function for goroutines
+	mu            sync.Mutex         // Guards the storage instance and the sync goroutine
+	basePath      string             // Base path for storage
+	storage       Storage            // Storage instance
+	customStorage Storage            // Storage instance provided by the user, if any
+	cancel        context.CancelFunc // Cancel function for the data points sync goroutine
+	syncWg        sync.WaitGroup     // Waits for the data points sync goroutine to stop
 )
 
 // Storage defines the methods required for storage operations.
@@ -59,49 +60,85 @@ func (s *StorageWrapper) Close() error {
 	return s.storage.Close()
 }
 
+// NewDiskStorage creates a disk-backed Storage under the data path, keeping the data points for the retention period.
+func NewDiskStorage(dataPath string, retention time.Duration) (Storage, error) {
+	tstorageInstance, err := tstorage.NewStorage(
+		tstorage.WithDataPath(dataPath),
+		tstorage.WithRetention(retention),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &StorageWrapper{storage: tstorageInstance}, nil
+}
+
+// SetStorage sets the Storage implementation to use instead of the default disk-backed storage.
+// It must be called before the storage is used for the first time.
+func SetStorage(s Storage) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	customStorage = s
+}
+
 // GetStorageInstance initializes and returns a Storage instance.
 func GetStorageInstance() (Storage, error) {
-	var err error
-	once.Do(func() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if storage != nil {
+		return storage, nil
+	}
+
+	if customStorage != nil {
+		storage = customStorage
+	} else {
 		basePath = common.GetBasePath()
-		tstorageInstance, err := tstorage.NewStorage(
-			tstorage.WithDataPath(basePath+"/data"),
-			tstorage.WithRetention(common.GetDataRetentionPeriod()),
-		)
+		diskStorage, err := NewDiskStorage(basePath+"/data", common.GetDataRetentionPeriod())
 		if err != nil {
-			log.Panicf("[MoniGo] Error initializing storage: %v\n", err)
+			return nil, fmt.Errorf("error initializing storage: %w", err) // Retrying on the next call
 		}
-		storage = &StorageWrapper{storage: tstorageInstance}
-
-		// Initialize context and cancel function for goroutines
-		ctx, cancel = context.WithCancel(context.Background())
-	})
-	return storage, err
+		storage = diskStorage
+	}
+	return storage, nil
 }
 
-// CloseStorage closes the storage instance and stops any running goroutines.
-func CloseStorage() {
-	closeOnce.Do(func() {
-		if cancel != nil {
-			cancel() // Stop any goroutines
-		}
-		if storage != nil {
-			if err := storage.Close(); err != nil {
-				log.Panicf("[MoniGo] Error closing storage: %v\n", err)
-			}
-		}
-	})
+// CloseStorage stops the data points sync goroutine and closes the storage instance.
+// The storage provided by the user through SetStorage is left open, it is owned by the user and used again on the next start.
+// The storage is initialized again the next time it is used.
+func CloseStorage() error {
+	StopDataPointsSync()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if storage == nil {
+		return nil
+	}
+	if storage == customStorage {
+		storage = nil
+		return nil
+	}
+
+	err := storage.Close()
+	storage = nil
+	if err != nil {
+		return fmt.Errorf("error closing storage: %w", err)
+	}
+	return nil
 }
 
 // PurgeStorage removes all storage data and closes the storage.
-func PurgeStorage() {
+func PurgeStorage() error {
 	basePath := common.GetBasePath()
 	if err := os.RemoveAll(basePath); err != nil {
-		log.Panicf("[MoniGo] Error purging storage: %v\n", err)
+		return fmt.Errorf("error purging storage: %w", err)
 	}
+	return nil
 }
 
 // SetDataPointsSyncFrequency sets the frequency at which data points are synchronized.
+// The error of storing the first data points is returned, the synchronization keeps running regardless.
 func SetDataPointsSyncFrequency(frequency ...string) error {
 	freqStr := "5m"
 	if len(frequency) > 0 {
@@ -114,14 +151,18 @@ func SetDataPointsSyncFrequency(frequency ...string) error {
 		freqTime = 5 * time.Minute
 	}
 
-	// Initializing service metrics once
-	serviceMetrics := core.GetServiceStats()
-	if err := StoreServiceMetrics(&serviceMetrics); err != nil {
-		return errors.New("[MoniGo] error storing service metrics, err: " + err.Error())
+	mu.Lock()
+	if cancel != nil {
+		cancel() // Stopping the previous sync goroutine, if any
 	}
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(context.Background())
+	mu.Unlock()
 
 	timer := time.NewTimer(freqTime)
+	syncWg.Add(1)
 	go func() {
+		defer syncWg.Done()
 		defer timer.Stop()
 		for {
 			select {
@@ -137,5 +178,23 @@ func SetDataPointsSyncFrequency(frequency ...string) error {
 		}
 	}()
 
+	// Initializing service metrics once, the sync goroutine keeps retrying if the storage is unavailable
+	serviceMetrics := core.GetServiceStats()
+	if err := StoreServiceMetrics(&serviceMetrics); err != nil {
+		return errors.New("[MoniGo] error storing service metrics, err: " + err.Error())
+	}
+
 	return nil
 }
+
+// StopDataPointsSync stops the data points sync goroutine and waits for it to return.
+func StopDataPointsSync() {
+	mu.Lock()
+	if cancel != nil {
+		cancel()
+		cancel = nil
+	}
+	mu.Unlock()
+
+	syncWg.Wait()
+}
@@ -1,12 +1,15 @@
 package timeseries
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/iyashjayesh/monigo/core"
 	"github.com/iyashjayesh/monigo/models"
 	"github.com/nakabonne/tstorage"
 )
@@ -17,7 +20,11 @@ func GetDataPoints(metric string, labels []tstorage.Label, start, end int64) ([]
 	if err != nil {
 		return nil, fmt.Errorf("error getting storage instance: %w", err)
 	}
-	return sto.Select(metric, labels, start, end)
+	datapoints, err := sto.Select(metric, labels, start, end)
+	if errors.Is(err, tstorage.ErrNoDataPoints) {
+		return nil, nil // A series without data points in the range is not an error for the callers
+	}
+	return datapoints, err
 }
 
 // StoreServiceMetrics stores service metrics in the time-series storage.
@@ -34,14 +41,7 @@ func StoreServiceMetrics(serviceMetrics *models.ServiceStats) error {
 
 	currentTime := time.Now().In(location)
 	timestamp := currentTime.Unix()
-	label := tstorage.Label{Name: "host", Value: "server1"}
-	var rows []tstorage.Row
-	rows = append(rows, generateCoreStatsRows(serviceMetrics, label, timestamp)...)
-	rows = append(rows, generateLoadStatsRows(serviceMetrics, label, timestamp)...)
-	rows = append(rows, generateCPUStatsRows(serviceMetrics, label, timestamp)...)
-	rows = append(rows, generateMemoryStatsRows(serviceMetrics, label, timestamp)...)
-	rows = append(rows, generateNetworkIORows(serviceMetrics, label, timestamp)...)
-	rows = append(rows, generateHealthStatsRows(serviceMetrics, label, timestamp)...)
+	rows := GenerateServiceMetricsRows(serviceMetrics, DefaultLabels(), timestamp)
 
 	if err := sto.InsertRows(rows); err != nil {
 		return fmt.Errorf("error storing service metrics: %w", err)
@@ -49,6 +49,25 @@ func StoreServiceMetrics(serviceMetrics *models.ServiceStats) error {
 	return nil
 }
 
+// GenerateServiceMetricsRows generates the rows stored for the service metrics.
+func GenerateServiceMetricsRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	var rows []tstorage.Row
+	rows = append(rows, generateCoreStatsRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateRequestStatsRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateLoadStatsRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateCPUStatsRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateMemoryStatsRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateNetworkIORows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateHealthStatsRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateCustomMetricsRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateFunctionRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateContainerRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateDiskIORows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateRuntimeRows(serviceMetrics, labels, timestamp)...)
+	rows = append(rows, generateCollectorRows(serviceMetrics, labels, timestamp)...)
+	return rows
+}
+
 // Helper function to remove percentage from a string.
 func RemovePercentage(s string) float64 {
 	var val float64
@@ -56,77 +75,143 @@ func RemovePercentage(s string) float64 {
 	return val
 }
 
-// Helper function to convert a string to a formatted float.
+// Helper function to convert a string to a float rounded to 4 decimals, 0 when the string is not a number.
 func StringToFloat(s string) float64 {
-	val, _ := strconv.ParseFloat(fmt.Sprintf("%.4f", s), 64)
+	val, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	val, _ = strconv.ParseFloat(fmt.Sprintf("%.4f", val), 64)
 	return val
 }
 
 // generateCoreStatsRows generates rows for core statistics.
-func generateCoreStatsRows(serviceMetrics *models.ServiceStats, label tstorage.Label, timestamp int64) []tstorage.Row {
+func generateCoreStatsRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
 	return []tstorage.Row{
 		{
 			Metric:    "goroutines",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: float64(serviceMetrics.CoreStatistics.Goroutines)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
-		// {
-		// 	Metric:    "request_count",
-		// 	DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: float64(serviceMetrics.CoreStatistics.RequestCount)},
-		// 	Labels:    []tstorage.Label{label},
-		// },
+		{
+			Metric:    "request_count",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: float64(serviceMetrics.CoreStatistics.RequestCount)},
+			Labels:    labels,
+		},
+		{
+			Metric:    "total_duration_took_by_request",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.CoreStatistics.TotalDurationTookByRequestMs},
+			Labels:    labels,
+		},
+		{
+			Metric:    "sampler_collection_duration",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.SamplerStatistics.LastCollectionMs},
+			Labels:    labels,
+		},
+		{
+			Metric:    "sampler_overhead_percent",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.SamplerStatistics.OverheadPercent},
+			Labels:    labels,
+		},
+	}
+}
+
+// generateRequestStatsRows generates rows for the request statistics of every route.
+func generateRequestStatsRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	var rows []tstorage.Row
+	for _, route := range serviceMetrics.RequestStatistics {
+		routeLabels := append(append([]tstorage.Label{}, labels...), tstorage.Label{Name: "route", Value: route.Route}, tstorage.Label{Name: "method", Value: route.Method})
+
+		for class, count := range route.StatusCodes {
+			rows = append(rows, tstorage.Row{
+				Metric:    "http_requests",
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: float64(count)},
+				Labels:    append(append([]tstorage.Label{}, routeLabels...), tstorage.Label{Name: "status_class", Value: class}),
+			})
+		}
+
+		rows = append(rows, []tstorage.Row{
+			{
+				Metric:    "http_request_latency_p50",
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: route.LatencyP50Ms},
+				Labels:    routeLabels,
+			},
+			{
+				Metric:    "http_request_latency_p90",
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: route.LatencyP90Ms},
+				Labels:    routeLabels,
+			},
+			{
+				Metric:    "http_request_latency_p99",
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: route.LatencyP99Ms},
+				Labels:    routeLabels,
+			},
+		}...)
 	}
+	return rows
 }
 
 // generateLoadStatsRows generates rows for load statistics.
-func generateLoadStatsRows(serviceMetrics *models.ServiceStats, label tstorage.Label, timestamp int64) []tstorage.Row {
+func generateLoadStatsRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
 
 	return []tstorage.Row{
 		{
 			Metric:    "overall_load_of_service",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: RemovePercentage(serviceMetrics.LoadStatistics.OverallLoadOfService)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "service_cpu_load",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: RemovePercentage(serviceMetrics.LoadStatistics.ServiceCPULoad)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "service_memory_load",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: RemovePercentage(serviceMetrics.LoadStatistics.ServiceMemLoad)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "system_cpu_load",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: RemovePercentage(serviceMetrics.LoadStatistics.SystemCPULoad)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "system_memory_load",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: RemovePercentage(serviceMetrics.LoadStatistics.SystemMemLoad)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
+		},
+		{
+			Metric:    "service_disk_load",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: RemovePercentage(serviceMetrics.LoadStatistics.ServiceDiskLoad)},
+			Labels:    labels,
+		},
+		{
+			Metric:    "system_disk_load",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: RemovePercentage(serviceMetrics.LoadStatistics.SystemDiskLoad)},
+			Labels:    labels,
+		},
+		{
+			Metric:    "total_disk_load",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: RemovePercentage(serviceMetrics.LoadStatistics.TotalDiskLoad)},
+			Labels:    labels,
 		},
 	}
 }
 
 // generateCPUStatsRows generates rows for CPU statistics.
-func generateCPUStatsRows(serviceMetrics *models.ServiceStats, label tstorage.Label, timestamp int64) []tstorage.Row {
+func generateCPUStatsRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
 	return []tstorage.Row{
 		{
 			Metric:    "total_cores",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.CPUStatistics.TotalCores},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "cores_used_by_service",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.CPUStatistics.CoresUsedByService},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "cores_used_by_system",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.CPUStatistics.CoresUsedBySystem},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 	}
 }
@@ -147,37 +232,37 @@ func extractFloat(s string) float64 {
 }
 
 // generateMemoryStatsRows generates rows for memory statistics.
-func generateMemoryStatsRows(serviceMetrics *models.ServiceStats, label tstorage.Label, timestamp int64) []tstorage.Row {
+func generateMemoryStatsRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
 	rows := []tstorage.Row{
 		{
 			Metric:    "total_system_memory",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.MemoryStatistics.TotalSystemMemory)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "memory_used_by_system",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.MemoryStatistics.MemoryUsedBySystem)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "memory_used_by_service",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.MemoryStatistics.MemoryUsedByService)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "available_memory",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.MemoryStatistics.AvailableMemory)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "gc_pause_duration",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.MemoryStatistics.GCPauseDuration)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "stack_memory_usage",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.MemoryStatistics.StackMemoryUsage)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 	}
 
@@ -186,7 +271,7 @@ func generateMemoryStatsRows(serviceMetrics *models.ServiceStats, label tstorage
 		rows = append(rows, tstorage.Row{
 			Metric:    record.RecordName,
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: record.RecordValue},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		})
 	}
 
@@ -195,55 +280,327 @@ func generateMemoryStatsRows(serviceMetrics *models.ServiceStats, label tstorage
 		{
 			Metric:    "heap_alloc_by_service",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.HeapAllocByService)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "heap_alloc_by_system",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.HeapAllocBySystem)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "total_alloc_by_service",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.TotalAllocByService)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "total_memory_by_os",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: extractFloat(serviceMetrics.TotalMemoryByOS)},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 	}...)
 	return rows
 }
 
-// generateNetworkIORows generates rows for network IO statistics.
-func generateNetworkIORows(serviceMetrics *models.ServiceStats, label tstorage.Label, timestamp int64) []tstorage.Row {
-	return []tstorage.Row{
+// generateNetworkIORows generates rows for network IO statistics, the totals and every interface labelled by its name.
+func generateNetworkIORows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	rows := []tstorage.Row{
 		{
 			Metric:    "bytes_sent",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.NetworkIO.BytesSent},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "bytes_received",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.NetworkIO.BytesReceived},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
+		{
+			Metric:    "bytes_sent_per_sec",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.NetworkIO.BytesSentPerSec},
+			Labels:    labels,
+		},
+		{
+			Metric:    "bytes_received_per_sec",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.NetworkIO.BytesReceivedPerSec},
+			Labels:    labels,
+		},
+	}
+
+	for _, iface := range serviceMetrics.NetworkIO.Interfaces {
+		ifaceLabels := append(append([]tstorage.Label{}, labels...), tstorage.Label{Name: "interface", Value: iface.Name})
+		for _, v := range []struct {
+			metric string
+			value  float64
+		}{
+			{"network_bytes_sent", float64(iface.BytesSent)},
+			{"network_bytes_received", float64(iface.BytesReceived)},
+			{"network_packets_sent", float64(iface.PacketsSent)},
+			{"network_packets_received", float64(iface.PacketsReceived)},
+			{"network_errors_in", float64(iface.ErrorsIn)},
+			{"network_errors_out", float64(iface.ErrorsOut)},
+			{"network_drops_in", float64(iface.DropsIn)},
+			{"network_drops_out", float64(iface.DropsOut)},
+			{"network_bytes_sent_per_sec", iface.BytesSentPerSec},
+			{"network_bytes_received_per_sec", iface.BytesReceivedPerSec},
+		} {
+			rows = append(rows, tstorage.Row{
+				Metric:    v.metric,
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: v.value},
+				Labels:    ifaceLabels,
+			})
+		}
 	}
+	return rows
 }
 
 // generateHealthStatsRows generates rows for service and system health statistics.
-func generateHealthStatsRows(serviceMetrics *models.ServiceStats, label tstorage.Label, timestamp int64) []tstorage.Row {
+func generateHealthStatsRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
 	return []tstorage.Row{
 		{
 			Metric:    "service_health_percent",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.Health.ServiceHealth.Percent},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 		{
 			Metric:    "system_health_percent",
 			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: serviceMetrics.Health.SystemHealth.Percent},
-			Labels:    []tstorage.Label{label},
+			Labels:    labels,
 		},
 	}
 }
+
+// generateContainerRows generates rows for the cgroup limits and usage, when the service runs in a cgroup.
+func generateContainerRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	container := serviceMetrics.Container
+	if container.CgroupVersion == 0 {
+		return nil
+	}
+
+	values := []struct {
+		metric string
+		value  float64
+	}{
+		{"container_memory_limit", float64(container.Memory.LimitBytes)},
+		{"container_memory_usage", float64(container.Memory.UsageBytes)},
+		{"container_memory_working_set", float64(container.Memory.WorkingSetBytes)},
+		{"container_memory_usage_percent", container.Memory.UsagePercent},
+		{"container_oom_events", float64(container.Memory.OOMEvents)},
+		{"container_oom_kills", float64(container.Memory.OOMKills)},
+		{"container_cpu_limit_cores", container.CPU.LimitCores},
+		{"container_cpu_usage_cores", container.CPU.UsageCores},
+		{"container_cpu_usage_percent", container.CPU.UsagePercent},
+		{"container_cpu_throttled_periods", float64(container.CPU.ThrottledPeriods)},
+		{"container_cpu_throttled_seconds", container.CPU.ThrottledSeconds},
+	}
+
+	rows := make([]tstorage.Row, 0, len(values))
+	for _, v := range values {
+		rows = append(rows, tstorage.Row{
+			Metric:    v.metric,
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: v.value},
+			Labels:    labels,
+		})
+	}
+	return rows
+}
+
+// FunctionLabels returns the labels the statistics of the traced function are stored with.
+func FunctionLabels(name string, defaultLabels []tstorage.Label) []tstorage.Label {
+	return append(append([]tstorage.Label{}, defaultLabels...), tstorage.Label{Name: "function", Value: name})
+}
+
+// generateFunctionRows generates rows for the traced functions labelled by the function name, the execution times are stored in milliseconds.
+func generateFunctionRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	var rows []tstorage.Row
+	for _, function := range serviceMetrics.Functions {
+		functionLabels := FunctionLabels(function.Name, labels)
+		for _, v := range []struct {
+			metric string
+			value  float64
+		}{
+			{"function_calls", float64(function.CallCount)},
+			{"function_errors", float64(function.ErrorCount)},
+			{"function_panics", float64(function.PanicCount)},
+			{"function_execution_time_min", function.ExecutionTimeMs.Min},
+			{"function_execution_time_max", function.ExecutionTimeMs.Max},
+			{"function_execution_time_mean", function.ExecutionTimeMs.Mean},
+			{"function_execution_time_p95", function.ExecutionTimeMs.P95},
+			{"function_execution_time_p99", function.ExecutionTimeMs.P99},
+			{"function_allocated_bytes_min", function.AllocatedBytes.Min},
+			{"function_allocated_bytes_max", function.AllocatedBytes.Max},
+			{"function_allocated_bytes_mean", function.AllocatedBytes.Mean},
+			{"function_allocated_bytes_p95", function.AllocatedBytes.P95},
+			{"function_allocated_bytes_p99", function.AllocatedBytes.P99},
+		} {
+			rows = append(rows, tstorage.Row{
+				Metric:    v.metric,
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: v.value},
+				Labels:    functionLabels,
+			})
+		}
+	}
+	return rows
+}
+
+// generateDiskIORows generates rows for the disk I/O of the service, of every device labelled by its name, and for the usage of the data volume.
+func generateDiskIORows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	diskIO := serviceMetrics.DiskIO
+
+	type value struct {
+		metric string
+		value  float64
+	}
+	values := []value{
+		{"process_read_bytes", float64(diskIO.Process.ReadBytes)},
+		{"process_write_bytes", float64(diskIO.Process.WriteBytes)},
+		{"process_read_ops", float64(diskIO.Process.ReadOps)},
+		{"process_write_ops", float64(diskIO.Process.WriteOps)},
+		{"process_read_bytes_per_sec", diskIO.Process.ReadBytesPerSec},
+		{"process_write_bytes_per_sec", diskIO.Process.WriteBytesPerSec},
+		{"filesystem_total_bytes", float64(diskIO.Filesystem.TotalBytes)},
+		{"filesystem_used_bytes", float64(diskIO.Filesystem.UsedBytes)},
+		{"filesystem_used_percent", diskIO.Filesystem.UsedPercent},
+		{"filesystem_inodes_used_percent", diskIO.Filesystem.InodesUsedPercent},
+	}
+
+	rows := make([]tstorage.Row, 0, len(values))
+	for _, v := range values {
+		rows = append(rows, tstorage.Row{
+			Metric:    v.metric,
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: v.value},
+			Labels:    labels,
+		})
+	}
+
+	for _, device := range diskIO.Devices {
+		deviceLabels := append(append([]tstorage.Label{}, labels...), tstorage.Label{Name: "device", Value: device.Name})
+		for _, v := range []value{
+			{"disk_read_bytes", float64(device.ReadBytes)},
+			{"disk_write_bytes", float64(device.WriteBytes)},
+			{"disk_read_ops", float64(device.ReadOps)},
+			{"disk_write_ops", float64(device.WriteOps)},
+			{"disk_read_bytes_per_sec", device.ReadBytesPerSec},
+			{"disk_write_bytes_per_sec", device.WriteBytesPerSec},
+			{"disk_read_ops_per_sec", device.ReadOpsPerSec},
+			{"disk_write_ops_per_sec", device.WriteOpsPerSec},
+			{"disk_utilization_percent", device.UtilizationPercent},
+		} {
+			rows = append(rows, tstorage.Row{
+				Metric:    v.metric,
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: v.value},
+				Labels:    deviceLabels,
+			})
+		}
+	}
+	return rows
+}
+
+// generateRuntimeRows generates rows for the runtime/metrics statistics.
+// The latency histograms are stored as <name>_count, <name>_p50/p90/p99 in milliseconds and <name>_bucket rows labelled by the upper bound in seconds.
+func generateRuntimeRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	runtimeStats := serviceMetrics.Runtime
+
+	var rows []tstorage.Row
+	for _, v := range []struct {
+		metric string
+		value  float64
+	}{
+		{"runtime_heap_goal", float64(runtimeStats.HeapGoalBytes)},
+		{"runtime_heap_live", float64(runtimeStats.HeapLiveBytes)},
+		{"runtime_memory_limit", float64(runtimeStats.MemoryLimitBytes)},
+		{"runtime_gc_cycles", float64(runtimeStats.GCCycles)},
+		{"runtime_mutex_wait", runtimeStats.MutexWaitMs},
+	} {
+		rows = append(rows, tstorage.Row{
+			Metric:    v.metric,
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: v.value},
+			Labels:    labels,
+		})
+	}
+
+	rows = append(rows, generateRuntimeHistogramRows("runtime_sched_latency", runtimeStats.SchedulerLatency, labels, timestamp)...)
+	rows = append(rows, generateRuntimeHistogramRows("runtime_gc_pause", runtimeStats.GCPauses, labels, timestamp)...)
+	return rows
+}
+
+// generateRuntimeHistogramRows generates the rows of a runtime latency histogram.
+func generateRuntimeHistogramRows(name string, histogram models.RuntimeHistogram, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	rows := []tstorage.Row{
+		{Metric: name + "_count", DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: float64(histogram.Count)}, Labels: labels},
+		{Metric: name + "_p50", DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: histogram.P50Ms}, Labels: labels},
+		{Metric: name + "_p90", DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: histogram.P90Ms}, Labels: labels},
+		{Metric: name + "_p99", DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: histogram.P99Ms}, Labels: labels},
+	}
+	buckets := append(append([]models.HistogramBucket{}, histogram.Buckets...), models.HistogramBucket{UpperBound: math.Inf(1), Count: histogram.Count})
+	for _, bucket := range buckets {
+		rows = append(rows, tstorage.Row{
+			Metric:    name + "_bucket",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: float64(bucket.Count)},
+			Labels:    append(append([]tstorage.Label{}, labels...), tstorage.Label{Name: "le", Value: strconv.FormatFloat(bucket.UpperBound, 'g', -1, 64)}),
+		})
+	}
+	return rows
+}
+
+// generateCollectorRows generates rows for the failed collections of every collector.
+func generateCollectorRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	var rows []tstorage.Row
+	for _, collector := range serviceMetrics.Collectors {
+		rows = append(rows, tstorage.Row{
+			Metric:    "collector_errors_total",
+			DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: float64(collector.ErrorCount)},
+			Labels:    append(append([]tstorage.Label{}, labels...), tstorage.Label{Name: "collector", Value: collector.Name}),
+		})
+	}
+	return rows
+}
+
+// CustomMetricLabels returns the labels the custom metric is stored with.
+func CustomMetricLabels(metric models.CustomMetric, defaultLabels []tstorage.Label) []tstorage.Label {
+	labels := append([]tstorage.Label{}, defaultLabels...)
+	for name, value := range metric.Labels {
+		labels = append(labels, tstorage.Label{Name: name, Value: value})
+	}
+	return labels
+}
+
+// generateCustomMetricsRows generates rows for the metrics recorded by the service.
+// Histograms are stored as <name>_count, <name>_sum, <name>_p50/p90/p99 and <name>_bucket rows labelled by the upper bound.
+func generateCustomMetricsRows(serviceMetrics *models.ServiceStats, labels []tstorage.Label, timestamp int64) []tstorage.Row {
+	var rows []tstorage.Row
+	for _, metric := range serviceMetrics.CustomMetrics {
+		metricLabels := CustomMetricLabels(metric, labels)
+		if metric.Type != core.HistogramMetric {
+			rows = append(rows, tstorage.Row{
+				Metric:    metric.Name,
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: metric.Value},
+				Labels:    metricLabels,
+			})
+			continue
+		}
+
+		for suffix, value := range map[string]float64{
+			"_count": float64(metric.Count),
+			"_sum":   metric.Sum,
+			"_p50":   metric.P50,
+			"_p90":   metric.P90,
+			"_p99":   metric.P99,
+		} {
+			rows = append(rows, tstorage.Row{
+				Metric:    metric.Name + suffix,
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: value},
+				Labels:    metricLabels,
+			})
+		}
+
+		buckets := append(append([]models.HistogramBucket{}, metric.Buckets...), models.HistogramBucket{UpperBound: math.Inf(1), Count: metric.Count})
+		for _, bucket := range buckets {
+			rows = append(rows, tstorage.Row{
+				Metric:    metric.Name + "_bucket",
+				DataPoint: tstorage.DataPoint{Timestamp: timestamp, Value: float64(bucket.Count)},
+				Labels:    append(append([]tstorage.Label{}, metricLabels...), tstorage.Label{Name: "le", Value: strconv.FormatFloat(bucket.UpperBound, 'g', -1, 64)}),
+			})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,25 @@
+package timeseries
+
+import "testing"
+
+func TestStringToFloat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"12", 12},
+		{"12.345678", 12.3457},
+		{" 3.5\n", 3.5},
+		{"-0.00004", 0},
+		{"1e3", 1000},
+		{"", 0},
+		{"12 MB", 0},
+		{"abc", 0},
+	}
+
+	for _, tt := range tests {
+		if got := StringToFloat(tt.in); got != tt.want {
+			t.Errorf("StringToFloat(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}